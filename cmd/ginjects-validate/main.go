@@ -0,0 +1,128 @@
+// Command ginjects-validate 校验依赖注入，不需要启动应用
+//
+// 生成一个导入指定包的临时 main 包（包在 init 函数中向默认容器注入实例），调用 ginjects.Validate()
+// 输出所有依赖注入的问题（字段路径）以及依赖的循环，存在问题时退出码为 1
+//
+// 用法（在模块的目录下执行）：
+//
+//	go run github.com/erkesi/gobean/cmd/ginjects-validate [-unused] [packages]
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	unused := flag.Bool("unused", false, "print the objects no other object depends on")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: ginjects-validate [-unused] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	os.Exit(run(patterns, *unused, os.Stdout, os.Stderr))
+}
+
+// run validates the packages, it returns the exit code.
+func run(patterns []string, unused bool, stdout, stderr io.Writer) int {
+	importPaths, err := listPackages(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	// The generated package lives in the current module to import its packages,
+	// the leading dot keeps it out of ./... patterns.
+	dir, err := os.MkdirTemp(".", ".ginjects-validate-")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	defer os.RemoveAll(dir)
+	var source bytes.Buffer
+	if err := mainTemplate.Execute(&source, struct {
+		ImportPaths []string
+		Unused      bool
+	}{importPaths, unused}); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), source.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir))
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
+
+// listPackages returns the import paths of the packages matched by the
+// patterns, main packages can't be imported so they are rejected.
+func listPackages(patterns []string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"list", "-f", "{{.Name}} {{.ImportPath}}"}, patterns...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ginjects-validate: go list failed: %v\n%s", err, stderr.String())
+	}
+	var importPaths []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, importPath, _ := strings.Cut(line, " ")
+		if name == "main" {
+			return nil, fmt.Errorf("ginjects-validate: package %s is a main package and can't be imported, "+
+				"provide the objects in an importable package", importPath)
+		}
+		importPaths = append(importPaths, importPath)
+	}
+	return importPaths, nil
+}
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by ginjects-validate. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erkesi/gobean/ginjects"
+{{range .ImportPaths}}	_ "{{.}}"
+{{end}})
+
+func main() {
+	report := ginjects.Validate()
+	for _, p := range report.Problems {
+		fmt.Println(p)
+	}
+{{- if .Unused}}
+	for _, unused := range report.Unused {
+		fmt.Println("unused:", unused)
+	}
+{{- end}}
+	if report.Err() != nil {
+		os.Exit(1)
+	}
+}
+`))
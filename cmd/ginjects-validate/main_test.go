@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"./testdata/broken"}, true, &stdout, &stderr)
+	ensure.DeepEqual(t, code, 1)
+	ensure.DeepEqual(t, stdout.String(), "*broken.Service.Repo.Cache: ginjects: did not find object named cache required by field Cache in type *broken.Repo\n"+
+		"*broken.Service.Name: ginjects: did not find config key service.name required by field Name in type *broken.Service\n"+
+		"unused: \"*broken.Service\"\n")
+}
+
+func TestListPackages(t *testing.T) {
+	importPaths, err := listPackages([]string{"./testdata/broken"})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, importPaths, []string{"github.com/erkesi/gobean/cmd/ginjects-validate/testdata/broken"})
+
+	_, err = listPackages([]string{"."})
+	ensure.Err(t, err, regexp.MustCompile("ginjects-validate: package github.com/erkesi/gobean/cmd/ginjects-validate is a main package"))
+}
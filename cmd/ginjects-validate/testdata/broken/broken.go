@@ -0,0 +1,23 @@
+// Package broken provides objects with wiring problems to the default container.
+package broken
+
+import "github.com/erkesi/gobean/ginjects"
+
+type DB struct{}
+
+type Repo struct {
+	DB    *DB    `inject:""`
+	Cache *Cache `inject:"cache"`
+}
+
+type Cache struct{}
+
+type Service struct {
+	Repo *Repo  `inject:""`
+	Name string `value:"service.name"`
+}
+
+func init() {
+	ginjects.ProvideByValue(&Service{})
+	ginjects.ProvideByValue(&DB{})
+}
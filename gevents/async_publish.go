@@ -0,0 +1,230 @@
+package gevents
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/erkesi/gobean/glogs"
+	"github.com/erkesi/gobean/grecovers"
+	"github.com/erkesi/gobean/gshares"
+)
+
+var (
+	// ErrQueueFull 队列已满（OverflowError）
+	ErrQueueFull = errors.New("gevents: async publisher queue is full")
+	// ErrEventDropped 队列已满，事件被丢弃（OverflowDrop）
+	ErrEventDropped = errors.New("gevents: event dropped, async publisher queue is full")
+	// ErrPublisherShutdown 发布器已经关闭
+	ErrPublisherShutdown = errors.New("gevents: async publisher is shut down")
+)
+
+// OverflowPolicy 队列已满时发布事件的处理策略
+type OverflowPolicy int
+
+const (
+	OverflowBlock OverflowPolicy = iota // 阻塞直到队列有空间，或者 ctx 结束（返回 ctx 的错误）
+	OverflowDrop                        // 丢弃事件，Publish 返回 nil，Future 的结果为 ErrEventDropped
+	OverflowError                       // 不发布事件，返回 ErrQueueFull
+)
+
+type asyncOptions struct {
+	workers   int
+	queueSize int
+	overflow  OverflowPolicy
+}
+
+type AsyncOption func(opt *asyncOptions)
+
+// WithAsyncWorkers 处理事件的协程数，默认为 1
+func WithAsyncWorkers(workers int) AsyncOption {
+	return func(opt *asyncOptions) {
+		opt.workers = workers
+	}
+}
+
+// WithAsyncQueueSize 队列的大小，默认为 1024
+func WithAsyncQueueSize(size int) AsyncOption {
+	return func(opt *asyncOptions) {
+		opt.queueSize = size
+	}
+}
+
+// WithAsyncOverflow 队列已满时的处理策略，默认为 OverflowBlock
+func WithAsyncOverflow(policy OverflowPolicy) AsyncOption {
+	return func(opt *asyncOptions) {
+		opt.overflow = policy
+	}
+}
+
+// Future 异步发布的事件的处理结果
+type Future struct {
+	done chan struct{}
+	err  error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+func (f *Future) complete(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// Done 事件处理完成时关闭
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err 事件处理的错误，需要在 Done 关闭之后调用
+func (f *Future) Err() error {
+	return f.err
+}
+
+// Wait 等待事件处理完成，返回事件处理的错误，ctx 结束时返回 ctx 的错误
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type asyncTask struct {
+	ctx    context.Context
+	event  interface{}
+	o      *pubOptions
+	future *Future
+}
+
+// AsyncPublisher 异步的事件发布器，事件放入有界的队列，由多个协程处理
+// ctx 的值传递给 Executor，ctx 的取消不会影响已经发布的事件
+type AsyncPublisher struct {
+	opt   *asyncOptions
+	queue chan *asyncTask
+	wg    sync.WaitGroup
+
+	// closing is closed by Shutdown, the queue is closed once the publishers
+	// in flight (counted by inflight under mu) have returned.
+	mu        sync.RWMutex
+	closing   chan struct{}
+	closeOnce sync.Once
+	inflight  sync.WaitGroup
+}
+
+// NewAsyncPublisher 创建异步的事件发布器，并启动处理事件的协程
+func NewAsyncPublisher(opts ...AsyncOption) *AsyncPublisher {
+	opt := &asyncOptions{workers: 1, queueSize: 1024}
+	for _, f := range opts {
+		f(opt)
+	}
+	if opt.workers <= 0 {
+		opt.workers = 1
+	}
+	if opt.queueSize < 0 {
+		opt.queueSize = 0
+	}
+	p := &AsyncPublisher{opt: opt, queue: make(chan *asyncTask, opt.queueSize), closing: make(chan struct{})}
+	p.wg.Add(opt.workers)
+	for i := 0; i < opt.workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Publish 发布事件，不等待事件处理完成
+func (p *AsyncPublisher) Publish(ctx context.Context, event interface{}, opts ...PublishOption) error {
+	_, err := p.PublishAsync(ctx, event, opts...)
+	return err
+}
+
+// PublishAsync 发布事件，返回事件处理的结果
+// @return error 队列已满（依据 OverflowPolicy）或者发布器已经关闭时的错误
+func (p *AsyncPublisher) PublishAsync(ctx context.Context, event interface{}, opts ...PublishOption) (*Future, error) {
+	o := &pubOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	task := &asyncTask{ctx: gshares.WithoutCancel(ctx), event: event, o: o, future: newFuture()}
+
+	p.mu.RLock()
+	select {
+	case <-p.closing:
+		p.mu.RUnlock()
+		return nil, ErrPublisherShutdown
+	default:
+	}
+	p.inflight.Add(1)
+	p.mu.RUnlock()
+	defer p.inflight.Done()
+
+	switch p.opt.overflow {
+	case OverflowDrop:
+		select {
+		case p.queue <- task:
+		default:
+			if glogs.Log != nil {
+				glogs.Log.Errorf(ctx, "gevents: dropped event `%T`, async publisher queue is full", event)
+			}
+			task.future.complete(ErrEventDropped)
+		}
+	case OverflowError:
+		select {
+		case p.queue <- task:
+		default:
+			return nil, ErrQueueFull
+		}
+	default:
+		select {
+		case p.queue <- task:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.closing:
+			return nil, ErrPublisherShutdown
+		}
+	}
+	return task.future, nil
+}
+
+// Shutdown 不再接收新的事件，阻塞在队列上的 Publish 返回 ErrPublisherShutdown，等待队列中的事件处理完成
+// @return error ctx 结束时返回 ctx 的错误，剩余的事件在后台继续处理
+func (p *AsyncPublisher) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+		go func() {
+			// Publishers that passed the closing check have been counted
+			// once the lock is acquired.
+			p.mu.Lock()
+			p.mu.Unlock()
+			p.inflight.Wait()
+			close(p.queue)
+		}()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *AsyncPublisher) work() {
+	defer p.wg.Done()
+	for task := range p.queue {
+		err := grecovers.RecoverOfErr(func() error {
+			return execute(task.ctx, task.event, task.o)
+		})
+		if err != nil && glogs.Log != nil {
+			glogs.Log.Errorf(task.ctx, "gevents: async execute event `%T` failed: %v", task.event, err)
+		}
+		task.future.complete(err)
+	}
+}
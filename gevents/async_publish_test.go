@@ -0,0 +1,213 @@
+package gevents
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type AsyncEvent struct {
+	Id int
+}
+
+type AsyncEventHandler struct {
+	started chan struct{}
+	release chan struct{}
+	handled int32
+}
+
+func (h *AsyncEventHandler) Execute(ctx context.Context, event interface{}) error {
+	if h.started != nil {
+		select {
+		case h.started <- struct{}{}:
+		default:
+		}
+	}
+	if h.release != nil {
+		<-h.release
+	}
+	if event.(*AsyncEvent).Id < 0 {
+		return errors.New("invalid id")
+	}
+	atomic.AddInt32(&h.handled, 1)
+	return nil
+}
+
+func (h *AsyncEventHandler) Types() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf(&AsyncEvent{})}
+}
+
+type AsyncPanicEvent struct{}
+
+type AsyncPanicEventHandler struct{}
+
+func (h *AsyncPanicEventHandler) Execute(ctx context.Context, event interface{}) error {
+	panic("handler panic")
+}
+
+func (h *AsyncPanicEventHandler) Types() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf(&AsyncPanicEvent{})}
+}
+
+var registerAsyncOnce sync.Once
+
+var asyncEventHandler = &AsyncEventHandler{}
+
+func registerAsync() {
+	registerAsyncOnce.Do(func() {
+		Register(asyncEventHandler)
+		Register(&AsyncPanicEventHandler{})
+	})
+}
+
+func TestAsyncPublish(t *testing.T) {
+	registerAsync()
+	asyncEventHandler.release = nil
+	atomic.StoreInt32(&asyncEventHandler.handled, 0)
+	publisher := NewAsyncPublisher(WithAsyncWorkers(4), WithAsyncQueueSize(16))
+	ctx, cancel := context.WithCancel(context.Background())
+	var futures []*Future
+	for i := 0; i < 10; i++ {
+		future, err := publisher.PublishAsync(ctx, &AsyncEvent{Id: i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, future)
+	}
+	// The events are not canceled with the publishing context.
+	cancel()
+	failed, err := publisher.PublishAsync(context.Background(), &AsyncEvent{Id: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	panicked, err := publisher.PublishAsync(context.Background(), &AsyncPanicEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := publisher.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, future := range futures {
+		if err := future.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&asyncEventHandler.handled); n != 10 {
+		t.Fatalf("handled:%d", n)
+	}
+	if err := failed.Wait(context.Background()); err == nil || err.Error() != "invalid id" {
+		t.Fatalf("err:%v", err)
+	}
+	<-panicked.Done()
+	if panicked.Err() == nil {
+		t.Fatal("panic not recovered")
+	}
+	if err := publisher.Publish(context.Background(), &AsyncEvent{}); !errors.Is(err, ErrPublisherShutdown) {
+		t.Fatalf("err:%v", err)
+	}
+}
+
+func TestAsyncPublishOverflow(t *testing.T) {
+	registerAsync()
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	asyncEventHandler.release, asyncEventHandler.started = release, started
+	defer func() {
+		asyncEventHandler.release, asyncEventHandler.started = nil, nil
+	}()
+
+	// The worker holds the first event, the queue holds the second.
+	fill := func(p *AsyncPublisher) {
+		if err := p.Publish(context.Background(), &AsyncEvent{Id: 0}); err != nil {
+			t.Fatal(err)
+		}
+		<-started
+		if err := p.Publish(context.Background(), &AsyncEvent{Id: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errPublisher := NewAsyncPublisher(WithAsyncQueueSize(1), WithAsyncOverflow(OverflowError))
+	fill(errPublisher)
+	if err := errPublisher.Publish(context.Background(), &AsyncEvent{}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("err:%v", err)
+	}
+
+	dropPublisher := NewAsyncPublisher(WithAsyncQueueSize(1), WithAsyncOverflow(OverflowDrop))
+	fill(dropPublisher)
+	dropped, err := dropPublisher.PublishAsync(context.Background(), &AsyncEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dropped.Wait(context.Background()); !errors.Is(err, ErrEventDropped) {
+		t.Fatalf("err:%v", err)
+	}
+
+	blockPublisher := NewAsyncPublisher(WithAsyncQueueSize(1))
+	fill(blockPublisher)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := blockPublisher.Publish(ctx, &AsyncEvent{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err:%v", err)
+	}
+
+	// Not drained before the deadline.
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := blockPublisher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err:%v", err)
+	}
+	close(release)
+	for _, p := range []*AsyncPublisher{errPublisher, dropPublisher, blockPublisher} {
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAsyncPublishShutdownBlocked(t *testing.T) {
+	registerAsync()
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	asyncEventHandler.release, asyncEventHandler.started = release, started
+	defer func() {
+		asyncEventHandler.release, asyncEventHandler.started = nil, nil
+	}()
+
+	publisher := NewAsyncPublisher(WithAsyncQueueSize(1))
+	if err := publisher.Publish(context.Background(), &AsyncEvent{Id: 0}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := publisher.Publish(context.Background(), &AsyncEvent{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// Blocked on the full queue.
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- publisher.Publish(context.Background(), &AsyncEvent{Id: 2})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := publisher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err:%v", err)
+	}
+	if cost := time.Since(start); cost > time.Second {
+		t.Fatalf("shutdown cost:%s", cost)
+	}
+	if err := <-blocked; !errors.Is(err, ErrPublisherShutdown) {
+		t.Fatalf("err:%v", err)
+	}
+	close(release)
+	if err := publisher.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -11,6 +11,11 @@ type Executor interface {
 }
 
 type executorExt struct {
-	executor Executor
-	priority int
+	executor        Executor
+	priority        int
+	seq             int
+	match           routeMatch
+	continueOnError bool
+	retry           *retryPolicy
+	deadLetter      DeadLetterHandler
 }
@@ -0,0 +1,101 @@
+// Package geventssqlite gevents 的发件箱（Outbox）的 SQLite 存储
+//
+// 不依赖 SQLite 驱动，调用方选择驱动打开数据库，如：github.com/mattn/go-sqlite3（需要 cgo）、modernc.org/sqlite：
+//
+//	db, err := sql.Open("sqlite3", "outbox.db")
+//	store, err := geventssqlite.NewOutboxStore(ctx, db)
+//	outbox := gevents.NewOutbox(store)
+package geventssqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/erkesi/gobean/gevents"
+)
+
+const outboxSchema = `CREATE TABLE IF NOT EXISTS gevents_outbox (
+	seq           INTEGER PRIMARY KEY AUTOINCREMENT,
+	id            TEXT    NOT NULL UNIQUE,
+	dedup_key     TEXT    NOT NULL UNIQUE,
+	type          TEXT    NOT NULL,
+	payload       BLOB    NOT NULL,
+	created_at    INTEGER NOT NULL,
+	attempts      INTEGER NOT NULL DEFAULT 0,
+	last_error    TEXT    NOT NULL DEFAULT '',
+	dispatched_at INTEGER
+)`
+
+// OutboxStore SQLite 的发件箱存储（表 gevents_outbox），分发成功（或者放弃）的事件保留用于去重
+type OutboxStore struct {
+	db *sql.DB
+}
+
+// NewOutboxStore 创建 SQLite 的发件箱存储，表不存在时创建
+// @param db *sql.DB "使用 SQLite 驱动打开的数据库，如：sql.Open("sqlite3", "outbox.db")"
+func NewOutboxStore(ctx context.Context, db *sql.DB) (*OutboxStore, error) {
+	if _, err := db.ExecContext(ctx, outboxSchema); err != nil {
+		return nil, fmt.Errorf("geventssqlite: create outbox table: %w", err)
+	}
+	return &OutboxStore{db: db}, nil
+}
+
+// Save tx 为 *sql.Tx，为 nil 时直接保存
+func (s *OutboxStore) Save(ctx context.Context, tx interface{}, records ...*gevents.OutboxRecord) error {
+	var exec interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	}
+	switch t := tx.(type) {
+	case nil:
+		exec = s.db
+	case *sql.Tx:
+		exec = t
+	default:
+		return fmt.Errorf("geventssqlite: outbox store expects a *sql.Tx but got type %T", tx)
+	}
+	for _, record := range records {
+		_, err := exec.ExecContext(ctx,
+			`INSERT OR IGNORE INTO gevents_outbox (id, dedup_key, type, payload, created_at) VALUES (?, ?, ?, ?, ?)`,
+			record.ID, record.Key, record.Type, record.Payload, record.CreatedAt.UnixNano())
+		if err != nil {
+			return fmt.Errorf("geventssqlite: save outbox event `%s`: %w", record.Type, err)
+		}
+	}
+	return nil
+}
+
+func (s *OutboxStore) Pending(ctx context.Context, limit int) ([]*gevents.OutboxRecord, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, dedup_key, type, payload, created_at, attempts, last_error FROM gevents_outbox
+		WHERE dispatched_at IS NULL ORDER BY seq LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*gevents.OutboxRecord
+	for rows.Next() {
+		record := &gevents.OutboxRecord{}
+		var createdAt int64
+		if err := rows.Scan(&record.ID, &record.Key, &record.Type, &record.Payload, &createdAt,
+			&record.Attempts, &record.LastError); err != nil {
+			return nil, err
+		}
+		record.CreatedAt = time.Unix(0, createdAt)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (s *OutboxStore) MarkDispatched(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE gevents_outbox SET dispatched_at = ? WHERE id = ?`,
+		time.Now().UnixNano(), id)
+	return err
+}
+
+func (s *OutboxStore) MarkFailed(ctx context.Context, id string, err error) error {
+	_, execErr := s.db.ExecContext(ctx, `UPDATE gevents_outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?`,
+		err.Error(), id)
+	return execErr
+}
@@ -0,0 +1,107 @@
+//go:build cgo
+
+package geventssqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erkesi/gobean/gevents"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type OrderPaidEvent struct {
+	Id int `json:"id"`
+}
+
+func TestOutboxStore(t *testing.T) {
+	var mu sync.Mutex
+	var ids []int
+	var keys []string
+	gevents.Subscribe(func(ctx context.Context, e *OrderPaidEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		key, _ := gevents.OutboxKey(ctx)
+		ids, keys = append(ids, e.Id), append(keys, key)
+		return nil
+	})
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := NewOutboxStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox := gevents.NewOutbox(store)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(ctx, tx, "order-1", &OrderPaidEvent{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(ctx, tx, "order-2", &OrderPaidEvent{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	// Deduplicated by key.
+	if err := outbox.Add(ctx, nil, "order-1", &OrderPaidEvent{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(ctx, "tx", "order-3", &OrderPaidEvent{Id: 3}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// A new outbox, as after a restart, resolves the type from the subscribers.
+	relay := gevents.NewOutbox(store, gevents.WithOutboxInterval(time.Millisecond))
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		relay.Run(runCtx)
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for {
+		pending, err := store.Pending(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ids) != 1 || ids[0] != 1 || keys[0] != "order-1" {
+		t.Fatalf("ids:%v keys:%v", ids, keys)
+	}
+	// Dispatched events are kept for deduplication.
+	if err := outbox.Add(ctx, nil, "order-1", &OrderPaidEvent{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := store.Pending(ctx, 10); len(pending) != 0 {
+		t.Fatalf("pending:%+v", pending)
+	}
+}
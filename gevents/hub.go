@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"sync"
 
 	"github.com/erkesi/gobean/ginjects"
 )
 
 type registerOptions struct {
-	priority int
+	priority        int
+	continueOnError bool
+	retry           *retryPolicy
+	deadLetter      DeadLetterHandler
 }
 
 type RegisterOption func(opt *registerOptions)
@@ -23,18 +27,16 @@ func WithRegisterPriority(priority int) RegisterOption {
 	}
 }
 
+// Register 注册事件处理器，只处理 Types 返回的类型的事件（不包括嵌入的 Struct、实现的 interface）
 func Register(executor Executor, opts ...RegisterOption) {
-	opt := &registerOptions{}
-	for _, f := range opts {
-		f(opt)
-	}
-	hub.register(&executorExt{
-		executor: executor,
-		priority: opt.priority})
+	ginjects.ProvideByValue(executor, ginjects.WithProvidePriorityTop1())
+	hub.register(newExecutorExt(executor, opts...))
 }
 
 func SetDefaultExecutor(executor Executor) {
 	ginjects.ProvideByValue(executor, ginjects.WithProvidePriorityTop1())
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
 	hub.defaultExecutor = executor
 }
 
@@ -47,39 +49,63 @@ func execute(ctx context.Context, event interface{}, o *pubOptions) error {
 	if eventType.Kind() == reflect.Ptr {
 		eventType = eventType.Elem()
 	}
-	exts, defaultExecute := hub.findExecutes(eventType)
-	if len(exts) == 0 && o.mustHaveSubscriber {
+	resolved, defaultExecute := hub.findExecutes(eventType)
+	// The interface and wildcard subscribers are not subscribers of the type.
+	if !resolved.typed && o.mustHaveSubscriber {
 		return fmt.Errorf("gevents: event type `%T`, not find executor", event)
 	}
+	exts := resolved.exts
+	if !resolved.typed && defaultExecute != nil {
+		exts = append(exts[:len(exts):len(exts)], &executorExt{executor: defaultExecute})
+	}
 	if len(exts) == 0 {
-		if defaultExecute == nil {
-			return fmt.Errorf("gevents: event type `%T`, not find executor", event)
-		} else {
-			return defaultExecute.Execute(ctx, event)
-		}
+		return fmt.Errorf("gevents: event type `%T`, not find executor", event)
 	}
-	var err error
+	var errs Errors
 	for _, ext := range exts {
-		err = ext.executor.Execute(ctx, event)
-		if err != nil {
-			break
+		if err := ext.execute(ctx, event); err != nil {
+			errs = append(errs, err)
+			if !ext.continueOnError {
+				break
+			}
 		}
 	}
-	return err
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errs
+	}
 }
 
 var hub = &_hub{}
 
 type _hub struct {
+	mu              sync.RWMutex
 	executes        map[reflect.Type][]*executorExt
 	defaultExecutor Executor
+	seq             int
+	// resolved caches the executors of the concrete event types, including
+	// the subscribers of the interfaces and embedded structs.
+	resolved map[reflect.Type]*resolution
+}
+
+// The executors of a concrete event type.
+type resolution struct {
+	exts  []*executorExt
+	typed bool // Whether an executor subscribed to the type or a struct it embeds
 }
 
 func (h *_hub) register(ext *executorExt) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if h.executes == nil {
 		h.executes = map[reflect.Type][]*executorExt{}
 	}
-	ginjects.ProvideByValue(ext.executor, ginjects.WithProvidePriorityTop1())
+	h.seq++
+	ext.seq = h.seq
 	eventTypeSet := map[reflect.Type]bool{}
 	for _, eventType := range ext.executor.Types() {
 		if eventType.Kind() == reflect.Ptr {
@@ -90,22 +116,66 @@ func (h *_hub) register(ext *executorExt) {
 			h.executes[eventType] = append(h.executes[eventType], ext)
 		}
 	}
-	for eventType := range eventTypeSet {
-		sort.Slice(h.executes[eventType], sortExecutes(h.executes[eventType]))
-	}
+	h.resolved = nil
 }
 
+// sortExecutes orders by priority, then by registration.
 func sortExecutes(exts []*executorExt) func(i int, j int) bool {
 	return func(i, j int) bool {
-		return exts[i].priority > exts[j].priority
+		if exts[i].priority != exts[j].priority {
+			return exts[i].priority > exts[j].priority
+		}
+		return exts[i].seq < exts[j].seq
 	}
 }
 
 func (h *_hub) clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	h.executes = nil
 	h.defaultExecutor = nil
+	h.resolved = nil
 }
 
-func (h *_hub) findExecutes(eventType reflect.Type) ([]*executorExt, Executor) {
-	return h.executes[eventType], h.defaultExecutor
+func (h *_hub) findExecutes(eventType reflect.Type) (*resolution, Executor) {
+	h.mu.RLock()
+	resolved, ok := h.resolved[eventType]
+	defaultExecutor := h.defaultExecutor
+	h.mu.RUnlock()
+	if ok {
+		return resolved, defaultExecutor
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if resolved, ok = h.resolved[eventType]; !ok {
+		resolved = h.resolve(eventType)
+		if h.resolved == nil {
+			h.resolved = map[reflect.Type]*resolution{}
+		}
+		h.resolved[eventType] = resolved
+	}
+	return resolved, h.defaultExecutor
+}
+
+// resolve merges the executors of the event type with the Subscribe, SubscribeAs
+// and SubscribeAll subscribers of the structs it embeds and of the interfaces
+// it (or its pointer) implements, h.mu must be held.
+func (h *_hub) resolve(eventType reflect.Type) *resolution {
+	resolved := &resolution{}
+	added := map[*executorExt]bool{}
+	for subscribed, subscribedExts := range h.executes {
+		for _, ext := range subscribedExts {
+			if added[ext] || !ext.matches(eventType, subscribed) {
+				continue
+			}
+			added[ext] = true
+			resolved.exts = append(resolved.exts, ext)
+			if ext.match != matchInterface {
+				resolved.typed = true
+			}
+		}
+	}
+	sort.Slice(resolved.exts, sortExecutes(resolved.exts))
+	return resolved
 }
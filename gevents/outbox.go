@@ -0,0 +1,375 @@
+package gevents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/erkesi/gobean/glogs"
+)
+
+// OutboxRecord 发件箱中序列化的事件
+type OutboxRecord struct {
+	ID        string    // 唯一标识
+	Key       string    // 去重的 key，相同 key 的事件只保存一次，处理时可以通过 OutboxKey(ctx) 获取
+	Type      string    // 事件的类型，如：github.com/pkg/events.OrderPaidEvent
+	Payload   []byte    // JSON 序列化的事件
+	CreatedAt time.Time //
+	Attempts  int       // 分发失败的次数
+	LastError string    // 最后一次分发失败的错误
+}
+
+// OutboxStore 发件箱的存储
+type OutboxStore interface {
+	// Save 在调用方的事务（tx）中保存事件，key 已经存在的事件被忽略
+	Save(ctx context.Context, tx interface{}, records ...*OutboxRecord) error
+	// Pending 按照保存的顺序返回最多 limit 个没有分发成功的事件
+	Pending(ctx context.Context, limit int) ([]*OutboxRecord, error)
+	// MarkDispatched 事件分发成功，不再返回
+	MarkDispatched(ctx context.Context, id string) error
+	// MarkFailed 事件分发失败，增加失败的次数
+	MarkFailed(ctx context.Context, id string, err error) error
+}
+
+type outboxOptions struct {
+	publisher   Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	deadLetter  OutboxDeadLetterHandler
+}
+
+// OutboxDeadLetterHandler 事件分发失败的次数达到 WithOutboxMaxAttempts 时调用
+// @param record *OutboxRecord "失败的事件，Attempts、LastError 包括最后一次的失败"
+type OutboxDeadLetterHandler func(ctx context.Context, record *OutboxRecord, err error)
+
+type OutboxOption func(opt *outboxOptions)
+
+// WithOutboxPublisher 分发事件的发布器，默认为 DefaultPublisher
+func WithOutboxPublisher(publisher Publisher) OutboxOption {
+	return func(opt *outboxOptions) {
+		opt.publisher = publisher
+	}
+}
+
+// WithOutboxInterval Run 轮询发件箱的间隔，默认为 1s
+func WithOutboxInterval(interval time.Duration) OutboxOption {
+	return func(opt *outboxOptions) {
+		opt.interval = interval
+	}
+}
+
+// WithOutboxBatchSize 每次从发件箱获取的事件数，默认为 100
+func WithOutboxBatchSize(size int) OutboxOption {
+	return func(opt *outboxOptions) {
+		opt.batchSize = size
+	}
+}
+
+// WithOutboxMaxAttempts 事件分发失败的次数达到 attempts 时，交给 handler（可以为 nil）处理，不再分发，默认为 0（一直重试）
+func WithOutboxMaxAttempts(attempts int, handler OutboxDeadLetterHandler) OutboxOption {
+	return func(opt *outboxOptions) {
+		opt.maxAttempts = attempts
+		opt.deadLetter = handler
+	}
+}
+
+// Outbox 事务性发件箱，事件在调用方的事务中保存，提交后由 Run 分发，至少分发一次
+// 分发时按照保存的类型名反序列化事件，类型来自 Add、RegisterTypes 以及按照类型注册的事件处理器
+// 重启之后，只通过 SubscribeAs、SubscribeAll 或者嵌入的 Struct 处理的事件的类型需要通过 RegisterTypes 注册，否则分发失败
+type Outbox struct {
+	store OutboxStore
+	opt   *outboxOptions
+
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// NewOutbox 创建发件箱，重启之后需要在 Run 之前调用 RegisterTypes 注册事件的类型（见 Outbox）
+func NewOutbox(store OutboxStore, opts ...OutboxOption) *Outbox {
+	opt := &outboxOptions{publisher: &DefaultPublisher{}, interval: time.Second, batchSize: 100}
+	for _, f := range opts {
+		f(opt)
+	}
+	return &Outbox{store: store, opt: opt, types: map[string]reflect.Type{}}
+}
+
+// RegisterTypes 注册事件的类型，用于反序列化事件
+// Add 保存的事件的类型，以及注册了处理器的事件的类型不需要注册
+func (o *Outbox) RegisterTypes(events ...interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, event := range events {
+		typ := eventTypeOf(event)
+		o.types[outboxTypeName(typ)] = typ
+	}
+}
+
+// Add 在调用方的事务中保存事件
+// @param tx interface "调用方的事务，如：geventssqlite.OutboxStore 为 *sql.Tx，MemoryOutboxStore 为 *MemoryOutboxTx，为 nil 时直接保存"
+// @param key string "去重的 key，为空时使用唯一标识"
+// @param event interface "事件，JSON 序列化"
+func (o *Outbox) Add(ctx context.Context, tx interface{}, key string, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("gevents: marshal outbox event `%T`: %w", event, err)
+	}
+	typ := eventTypeOf(event)
+	name := outboxTypeName(typ)
+	o.mu.Lock()
+	o.types[name] = typ
+	o.mu.Unlock()
+
+	id := newOutboxID()
+	if key == "" {
+		key = id
+	}
+	return o.store.Save(ctx, tx, &OutboxRecord{
+		ID:        id,
+		Key:       key,
+		Type:      name,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Run 轮询发件箱并分发事件，直到 ctx 结束，通常在单独的协程中运行
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.opt.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := o.Dispatch(ctx); err != nil && glogs.Log != nil {
+			glogs.Log.Errorf(ctx, "%v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch 分发发件箱中全部没有分发成功的事件，分发失败的事件在下一次分发时重试
+// @return int 分发成功的事件数
+// @return error 存储的错误
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	var dispatched int
+	failed := map[string]bool{}
+	for ctx.Err() == nil {
+		records, err := o.store.Pending(ctx, o.opt.batchSize+len(failed))
+		if err != nil {
+			return dispatched, fmt.Errorf("gevents: outbox pending: %w", err)
+		}
+		var progressed bool
+		for _, record := range records {
+			if failed[record.ID] {
+				continue
+			}
+			progressed = true
+			if err := o.dispatch(ctx, record); err != nil {
+				failed[record.ID] = true
+				if glogs.Log != nil {
+					glogs.Log.Errorf(ctx, "gevents: dispatch outbox event `%s` key `%s` failed: %v", record.Type, record.Key, err)
+				}
+				if err := o.fail(ctx, record, err); err != nil {
+					return dispatched, err
+				}
+				continue
+			}
+			if err := o.store.MarkDispatched(ctx, record.ID); err != nil {
+				return dispatched, fmt.Errorf("gevents: outbox mark dispatched: %w", err)
+			}
+			dispatched++
+		}
+		if !progressed {
+			break
+		}
+	}
+	return dispatched, nil
+}
+
+// fail records the failed dispatch, the record is handed to the dead-letter
+// handler and no longer dispatched once it reaches the max attempts.
+func (o *Outbox) fail(ctx context.Context, record *OutboxRecord, err error) error {
+	if o.opt.maxAttempts <= 0 || record.Attempts+1 < o.opt.maxAttempts {
+		if err := o.store.MarkFailed(ctx, record.ID, err); err != nil {
+			return fmt.Errorf("gevents: outbox mark failed: %w", err)
+		}
+		return nil
+	}
+	record.Attempts++
+	record.LastError = err.Error()
+	if glogs.Log != nil {
+		glogs.Log.Errorf(ctx, "gevents: give up outbox event `%s` key `%s` after %d attempts", record.Type, record.Key, record.Attempts)
+	}
+	if o.opt.deadLetter != nil {
+		o.opt.deadLetter(ctx, record, err)
+	}
+	if err := o.store.MarkDispatched(ctx, record.ID); err != nil {
+		return fmt.Errorf("gevents: outbox mark dispatched: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) dispatch(ctx context.Context, record *OutboxRecord) error {
+	typ := o.lookupType(record.Type)
+	if typ == nil {
+		return fmt.Errorf("gevents: outbox event type `%s` is not registered, use Outbox.RegisterTypes", record.Type)
+	}
+	event := reflect.New(typ)
+	if err := json.Unmarshal(record.Payload, event.Interface()); err != nil {
+		return fmt.Errorf("gevents: unmarshal outbox event `%s`: %w", record.Type, err)
+	}
+	return o.opt.publisher.Publish(context.WithValue(ctx, outboxKeyCtx{}, record.Key), event.Interface())
+}
+
+// lookupType returns the type registered to the outbox, or subscribed in the
+// hub, of the name.
+func (o *Outbox) lookupType(name string) reflect.Type {
+	o.mu.RLock()
+	typ := o.types[name]
+	o.mu.RUnlock()
+	if typ != nil {
+		return typ
+	}
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	for eventType := range hub.executes {
+		if outboxTypeName(eventType) == name {
+			return eventType
+		}
+	}
+	return nil
+}
+
+type outboxKeyCtx struct{}
+
+// OutboxKey 返回发件箱分发的事件的去重 key，用于事件处理器的幂等处理
+func OutboxKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(outboxKeyCtx{}).(string)
+	return key, ok
+}
+
+func eventTypeOf(event interface{}) reflect.Type {
+	typ := reflect.TypeOf(event)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func outboxTypeName(typ reflect.Type) string {
+	if typ.PkgPath() == "" {
+		return typ.String()
+	}
+	return typ.PkgPath() + "." + typ.Name()
+}
+
+func newOutboxID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MemoryOutboxStore 内存的发件箱存储，用于测试，只对没有分发成功（或者放弃）的事件去重
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	records []*OutboxRecord
+	keys    map[string]bool // The keys of the pending records
+}
+
+// MemoryOutboxTx MemoryOutboxStore 的事务，Commit 之后保存的事件才可以分发
+type MemoryOutboxTx struct {
+	store   *MemoryOutboxStore
+	records []*OutboxRecord
+}
+
+// NewMemoryOutboxStore 创建内存的发件箱存储
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{keys: map[string]bool{}}
+}
+
+// Begin 开始事务
+func (s *MemoryOutboxStore) Begin() *MemoryOutboxTx {
+	return &MemoryOutboxTx{store: s}
+}
+
+// Commit 提交事务
+func (tx *MemoryOutboxTx) Commit() {
+	tx.store.save(tx.records...)
+	tx.records = nil
+}
+
+// Rollback 回滚事务
+func (tx *MemoryOutboxTx) Rollback() {
+	tx.records = nil
+}
+
+func (s *MemoryOutboxStore) Save(ctx context.Context, tx interface{}, records ...*OutboxRecord) error {
+	switch t := tx.(type) {
+	case nil:
+		s.save(records...)
+	case *MemoryOutboxTx:
+		t.records = append(t.records, records...)
+	default:
+		return fmt.Errorf("gevents: memory outbox store expects a *MemoryOutboxTx but got type %T", tx)
+	}
+	return nil
+}
+
+func (s *MemoryOutboxStore) save(records ...*OutboxRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range records {
+		if s.keys[record.Key] {
+			continue
+		}
+		s.keys[record.Key] = true
+		copied := *record
+		s.records = append(s.records, &copied)
+	}
+}
+
+func (s *MemoryOutboxStore) Pending(ctx context.Context, limit int) ([]*OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []*OutboxRecord
+	for _, record := range s.records {
+		if len(records) == limit {
+			break
+		}
+		copied := *record
+		records = append(records, &copied)
+	}
+	return records, nil
+}
+
+func (s *MemoryOutboxStore) MarkDispatched(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, record := range s.records {
+		if record.ID == id {
+			s.records = append(s.records[:i], s.records[i+1:]...)
+			delete(s.keys, record.Key)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemoryOutboxStore) MarkFailed(ctx context.Context, id string, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range s.records {
+		if record.ID == id {
+			record.Attempts++
+			record.LastError = err.Error()
+		}
+	}
+	return nil
+}
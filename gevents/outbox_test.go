@@ -0,0 +1,131 @@
+package gevents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type OutboxOrderEvent struct {
+	Id int `json:"id"`
+}
+
+type outboxHandler struct {
+	mu   sync.Mutex
+	keys []string
+	ids  []int
+	fail int
+}
+
+func (h *outboxHandler) handle(ctx context.Context, e *OutboxOrderEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fail > 0 {
+		h.fail--
+		return errors.New("broker unavailable")
+	}
+	key, _ := OutboxKey(ctx)
+	h.keys = append(h.keys, key)
+	h.ids = append(h.ids, e.Id)
+	return nil
+}
+
+func (h *outboxHandler) reset(fail int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.keys, h.ids, h.fail = nil, nil, fail
+}
+
+var (
+	outboxOnce         sync.Once
+	outboxOrderHandler = &outboxHandler{}
+)
+
+func subscribeOutbox() {
+	outboxOnce.Do(func() {
+		Subscribe(outboxOrderHandler.handle)
+	})
+}
+
+func TestOutboxMemory(t *testing.T) {
+	subscribeOutbox()
+	outboxOrderHandler.reset(1)
+	store := NewMemoryOutboxStore()
+	outbox := NewOutbox(store)
+	ctx := context.Background()
+
+	tx := store.Begin()
+	if err := outbox.Add(ctx, tx, "order-1", &OutboxOrderEvent{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := outbox.Dispatch(ctx); err != nil || n != 0 {
+		t.Fatalf("dispatched before commit:%d, %v", n, err)
+	}
+	tx.Commit()
+
+	rollback := store.Begin()
+	if err := outbox.Add(ctx, rollback, "order-2", &OutboxOrderEvent{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+	rollback.Rollback()
+	// Deduplicated by key.
+	if err := outbox.Add(ctx, nil, "order-1", &OutboxOrderEvent{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(ctx, nil, "", OutboxOrderEvent{Id: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first dispatch fails and is retried.
+	if n, err := outbox.Dispatch(ctx); err != nil || n != 1 {
+		t.Fatalf("dispatched:%d, %v", n, err)
+	}
+	pending, _ := store.Pending(ctx, 10)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError != "broker unavailable" {
+		t.Fatalf("pending:%+v", pending)
+	}
+	if n, err := outbox.Dispatch(ctx); err != nil || n != 1 {
+		t.Fatalf("dispatched:%d, %v", n, err)
+	}
+	if len(outboxOrderHandler.ids) != 2 || outboxOrderHandler.ids[0] != 3 || outboxOrderHandler.ids[1] != 1 {
+		t.Fatalf("ids:%v", outboxOrderHandler.ids)
+	}
+	if outboxOrderHandler.keys[1] != "order-1" {
+		t.Fatalf("keys:%v", outboxOrderHandler.keys)
+	}
+	// The keys of the dispatched events are not kept.
+	if len(store.keys) != 0 {
+		t.Fatalf("keys:%v", store.keys)
+	}
+}
+
+func TestOutboxUnknownType(t *testing.T) {
+	store := NewMemoryOutboxStore()
+	ctx := context.Background()
+	if err := store.Save(ctx, nil, &OutboxRecord{ID: "1", Key: "1", Type: "pkg.Unknown", Payload: []byte("{}")}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := NewOutbox(store).Dispatch(ctx); err != nil || n != 0 {
+		t.Fatalf("dispatched:%d, %v", n, err)
+	}
+	pending, _ := store.Pending(ctx, 10)
+	if len(pending) != 1 || pending[0].Attempts != 1 {
+		t.Fatalf("pending:%+v", pending)
+	}
+
+	// Given up after the max attempts.
+	var dead *OutboxRecord
+	outbox := NewOutbox(store, WithOutboxMaxAttempts(2, func(ctx context.Context, record *OutboxRecord, err error) {
+		dead = record
+	}))
+	if n, err := outbox.Dispatch(ctx); err != nil || n != 0 {
+		t.Fatalf("dispatched:%d, %v", n, err)
+	}
+	if dead == nil || dead.Attempts != 2 || dead.Key != "1" {
+		t.Fatalf("dead:%+v", dead)
+	}
+	if pending, _ := store.Pending(ctx, 10); len(pending) != 0 {
+		t.Fatalf("pending:%+v", pending)
+	}
+}
@@ -0,0 +1,115 @@
+package gevents
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/erkesi/gobean/glogs"
+)
+
+// DeadLetterHandler 事件处理器（包括重试）最终失败时调用
+// @param event interface "失败的事件"
+// @param executor Executor "失败的事件处理器，Subscribe 的处理函数为 gevents 的适配器"
+// @param err error "最后一次执行的错误"
+type DeadLetterHandler func(ctx context.Context, event interface{}, executor Executor, err error)
+
+type retryPolicy struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// WithRegisterContinueOnError 事件处理器失败时继续执行后面的事件处理器，发布返回全部的错误（Errors）
+func WithRegisterContinueOnError() RegisterOption {
+	return func(opt *registerOptions) {
+		opt.continueOnError = true
+	}
+}
+
+// WithRegisterRetry 事件处理器失败时重试，重试的间隔从 backoff 开始指数增长，不超过 maxBackoff（为 0 时不限制）
+// ctx 结束时不再重试
+// @param attempts int "最多执行的次数，包括第一次"
+func WithRegisterRetry(attempts int, backoff, maxBackoff time.Duration) RegisterOption {
+	return func(opt *registerOptions) {
+		opt.retry = &retryPolicy{attempts: attempts, backoff: backoff, maxBackoff: maxBackoff}
+	}
+}
+
+// WithRegisterDeadLetter 事件处理器（包括重试）最终失败时调用 handler，错误仍然返回给发布方
+func WithRegisterDeadLetter(handler DeadLetterHandler) RegisterOption {
+	return func(opt *registerOptions) {
+		opt.deadLetter = handler
+	}
+}
+
+// Errors 多个事件处理器的错误，WithRegisterContinueOnError 的事件处理器失败之后，后面的事件处理器也失败时返回
+type Errors []error
+
+func (es Errors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, err := range es {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (es Errors) Unwrap() []error {
+	return es
+}
+
+func newExecutorExt(executor Executor, opts ...RegisterOption) *executorExt {
+	opt := &registerOptions{}
+	for _, f := range opts {
+		f(opt)
+	}
+	return &executorExt{
+		executor:        executor,
+		priority:        opt.priority,
+		continueOnError: opt.continueOnError,
+		retry:           opt.retry,
+		deadLetter:      opt.deadLetter,
+	}
+}
+
+// execute runs the executor with the retry policy, and hands the final error
+// to the dead-letter handler.
+func (ext *executorExt) execute(ctx context.Context, event interface{}) error {
+	err := ext.executor.Execute(ctx, event)
+	if err != nil && ext.retry != nil {
+		backoff := ext.retry.backoff
+		for attempt := 2; err != nil && attempt <= ext.retry.attempts; attempt++ {
+			if glogs.Log != nil {
+				glogs.Log.Debugf(ctx, "gevents: retry event `%T` executor `%T` after %s, attempt %d: %v",
+					event, ext.executor, backoff, attempt, err)
+			}
+			if !sleep(ctx, backoff) {
+				break
+			}
+			err = ext.executor.Execute(ctx, event)
+			backoff *= 2
+			if ext.retry.maxBackoff > 0 && backoff > ext.retry.maxBackoff {
+				backoff = ext.retry.maxBackoff
+			}
+		}
+	}
+	if err != nil && ext.deadLetter != nil {
+		ext.deadLetter(ctx, event, ext.executor, err)
+	}
+	return err
+}
+
+// sleep waits for d, it returns false when ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
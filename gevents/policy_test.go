@@ -0,0 +1,119 @@
+package gevents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type PolicyContinueEvent struct {
+	Id int
+}
+
+type PolicyRetryEvent struct {
+	Id int
+}
+
+type PolicyStopEvent struct {
+	Id int
+}
+
+var (
+	policyOnce                    sync.Once
+	errPolicyFirst, errPolicyStop = errors.New("first"), errors.New("stop")
+	errPolicySecond               = errors.New("second")
+	policyCalled                  []int
+	policyAttempts                int
+	policyDeadEvent               interface{}
+	policyDeadErr                 error
+	policyDeadExecutor            Executor
+	policyStopCalled              bool
+)
+
+func subscribePolicy() {
+	policyOnce.Do(func() {
+		Subscribe(func(ctx context.Context, e *PolicyContinueEvent) error {
+			policyCalled = append(policyCalled, 1)
+			return errPolicyFirst
+		}, WithRegisterPriority(3), WithRegisterContinueOnError())
+		Subscribe(func(ctx context.Context, e *PolicyContinueEvent) error {
+			policyCalled = append(policyCalled, 2)
+			return errPolicySecond
+		}, WithRegisterPriority(2))
+		Subscribe(func(ctx context.Context, e *PolicyContinueEvent) error {
+			policyCalled = append(policyCalled, 3)
+			return nil
+		}, WithRegisterPriority(1))
+
+		Subscribe(func(ctx context.Context, e *PolicyRetryEvent) error {
+			policyAttempts++
+			if e.Id == 1 && policyAttempts == 2 {
+				return nil
+			}
+			return errors.New("unavailable")
+		}, WithRegisterRetry(3, time.Millisecond, 2*time.Millisecond),
+			WithRegisterDeadLetter(func(ctx context.Context, event interface{}, executor Executor, err error) {
+				policyDeadEvent, policyDeadExecutor, policyDeadErr = event, executor, err
+			}))
+
+		Subscribe(func(ctx context.Context, e *PolicyStopEvent) error {
+			return errPolicyStop
+		}, WithRegisterPriority(2))
+		Subscribe(func(ctx context.Context, e *PolicyStopEvent) error {
+			policyStopCalled = true
+			return nil
+		}, WithRegisterPriority(1))
+	})
+}
+
+func TestExecutorContinueOnError(t *testing.T) {
+	subscribePolicy()
+	policyCalled = nil
+	err := Publish(context.Background(), nil, &PolicyContinueEvent{Id: 1})
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0] != errPolicyFirst || errs[1] != errPolicySecond {
+		t.Fatalf("err:%v", err)
+	}
+	// The second executor stops the chain.
+	if len(policyCalled) != 2 {
+		t.Fatalf("called:%v", policyCalled)
+	}
+}
+
+func TestExecutorRetryAndDeadLetter(t *testing.T) {
+	subscribePolicy()
+	policyAttempts, policyDeadEvent, policyDeadErr, policyDeadExecutor = 0, nil, nil, nil
+	if err := Publish(context.Background(), nil, &PolicyRetryEvent{Id: 1}); err != nil || policyAttempts != 2 {
+		t.Fatalf("attempts:%d, err:%v", policyAttempts, err)
+	}
+	if policyDeadEvent != nil {
+		t.Fatalf("dead letter:%v", policyDeadEvent)
+	}
+
+	policyAttempts = 0
+	err := Publish(context.Background(), nil, &PolicyRetryEvent{Id: 2})
+	if err == nil || policyAttempts != 3 {
+		t.Fatalf("attempts:%d, err:%v", policyAttempts, err)
+	}
+	if e, ok := policyDeadEvent.(*PolicyRetryEvent); !ok || e.Id != 2 || policyDeadErr != err || policyDeadExecutor == nil {
+		t.Fatalf("dead letter:%v, %v", policyDeadEvent, policyDeadErr)
+	}
+
+	// No retry once ctx is done.
+	policyAttempts = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Publish(ctx, nil, &PolicyRetryEvent{Id: 3}); err == nil || policyAttempts != 1 {
+		t.Fatalf("attempts:%d, err:%v", policyAttempts, err)
+	}
+}
+
+func TestExecutorStopOnError(t *testing.T) {
+	subscribePolicy()
+	policyStopCalled = false
+	if err := Publish(context.Background(), nil, &PolicyStopEvent{}); err != errPolicyStop || policyStopCalled {
+		t.Fatalf("called:%v, err:%v", policyStopCalled, err)
+	}
+}
@@ -0,0 +1,87 @@
+package gevents
+
+import (
+	"reflect"
+)
+
+// How the events are matched to the type an executor subscribed to.
+type routeMatch int
+
+const (
+	matchExact     routeMatch = iota // Register: the same type
+	matchEmbedded                    // Subscribe: the same type, or a type embedding the struct
+	matchInterface                   // SubscribeAs, SubscribeAll: a type implementing the interface
+)
+
+// matches reports whether the events of the type are routed to the executor
+// subscribed to the subscribed type.
+func (ext *executorExt) matches(eventType, subscribed reflect.Type) bool {
+	if eventType == subscribed {
+		return true
+	}
+	switch ext.match {
+	case matchEmbedded:
+		return subscribed.Kind() == reflect.Struct && embeddedIndex(eventType, subscribed, map[reflect.Type]bool{}) != nil
+	case matchInterface:
+		return subscribed.Kind() == reflect.Interface && reflect.PtrTo(eventType).Implements(subscribed)
+	default:
+		return false
+	}
+}
+
+// embeddedIndex returns the index sequence of the exported struct, or pointer
+// to struct, embedded in typ directly or through other embedded structs.
+func embeddedIndex(typ, target reflect.Type, visited map[reflect.Type]bool) []int {
+	if typ.Kind() != reflect.Struct || visited[typ] {
+		return nil
+	}
+	visited[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.Anonymous || field.PkgPath != "" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType == target {
+			return []int{i}
+		}
+		if index := embeddedIndex(fieldType, target, visited); index != nil {
+			return append([]int{i}, index...)
+		}
+	}
+	return nil
+}
+
+// embeddedValue returns the pointer to the target struct embedded in the
+// event, and whether the event embeds it. The value is invalid when an
+// embedded pointer is nil.
+func embeddedValue(event interface{}, target reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(event)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	} else {
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		v = copied
+	}
+	index := embeddedIndex(v.Type(), target, map[reflect.Type]bool{})
+	if index == nil {
+		return reflect.Value{}, false
+	}
+	for _, i := range index {
+		v = v.Field(i)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, true
+			}
+			v = v.Elem()
+		}
+	}
+	return v.Addr(), true
+}
@@ -0,0 +1,178 @@
+package gevents
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type DomainEvent interface {
+	AggregateId() int
+}
+
+type BaseDomainEvent struct {
+	Aggregate int
+}
+
+func (e *BaseDomainEvent) AggregateId() int {
+	if e == nil {
+		return 0
+	}
+	return e.Aggregate
+}
+
+type AccountOpenedEvent struct {
+	BaseDomainEvent
+	Owner string
+}
+
+type AccountClosedEvent struct {
+	*BaseDomainEvent
+}
+
+type AccountFrozenEvent struct{}
+
+type AccountAuditedEvent struct{}
+
+func (e AccountAuditedEvent) AggregateId() int {
+	return 0
+}
+
+// recordExecutor records the events of the types it registered for.
+type recordExecutor struct {
+	name   string
+	types  []reflect.Type
+	called *[]string
+}
+
+func (h *recordExecutor) Execute(ctx context.Context, event interface{}) error {
+	*h.called = append(*h.called, h.name)
+	return nil
+}
+
+func (h *recordExecutor) Types() []reflect.Type {
+	return h.types
+}
+
+// isolateHub runs the test on an empty hub, the subscribers of the other tests
+// are restored on cleanup.
+func isolateHub(t *testing.T) {
+	saved := hub
+	hub = &_hub{}
+	t.Cleanup(func() {
+		hub = saved
+	})
+}
+
+func subscribeRoute(called *[]string) {
+	Subscribe(func(ctx context.Context, e *AccountOpenedEvent) error {
+		*called = append(*called, "exact:"+e.Owner)
+		return nil
+	}, WithRegisterPriority(2))
+	SubscribeAs(func(ctx context.Context, e DomainEvent) error {
+		*called = append(*called, "interface")
+		return nil
+	}, WithRegisterPriority(3))
+	Subscribe(func(ctx context.Context, e *BaseDomainEvent) error {
+		e.Aggregate++
+		*called = append(*called, "embedded")
+		return nil
+	}, WithRegisterPriority(1))
+	SubscribeAll(func(ctx context.Context, e interface{}) error {
+		*called = append(*called, "all")
+		return nil
+	})
+	// Executors of Register only handle the exact types, registered without
+	// ginjects as the tests run on isolated hubs.
+	hub.register(newExecutorExt(&recordExecutor{
+		name:   "register:base",
+		types:  []reflect.Type{reflect.TypeOf(&BaseDomainEvent{}), reflect.TypeOf((*DomainEvent)(nil))},
+		called: called,
+	}, WithRegisterPriority(4)))
+}
+
+func TestRouteHierarchy(t *testing.T) {
+	isolateHub(t)
+	var called []string
+	subscribeRoute(&called)
+	event := &AccountOpenedEvent{BaseDomainEvent: BaseDomainEvent{Aggregate: 1}, Owner: "zhaoche"}
+	if err := Publish(context.Background(), nil, event, WithMustHaveSubscriber()); err != nil {
+		t.Fatal(err)
+	}
+	// Priority 3, 2, 1 and 0.
+	expected := []string{"interface", "exact:zhaoche", "embedded", "all"}
+	if !reflect.DeepEqual(called, expected) {
+		t.Fatalf("called:%v", called)
+	}
+	if event.Aggregate != 2 {
+		t.Fatalf("aggregate:%d", event.Aggregate)
+	}
+
+	// Resolved from the cache, published by value.
+	called = nil
+	if err := (&DefaultPublisher{}).Publish(context.Background(), *event); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(called, expected) {
+		t.Fatalf("called:%v", called)
+	}
+	if _, ok := hub.resolved[reflect.TypeOf(*event)]; !ok {
+		t.Fatal("resolution is not cached")
+	}
+
+	called = nil
+	if err := Publish(context.Background(), nil, &BaseDomainEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(called, []string{"register:base", "interface", "embedded", "all"}) {
+		t.Fatalf("called:%v", called)
+	}
+}
+
+func TestRouteEmbeddedPointer(t *testing.T) {
+	isolateHub(t)
+	var called []string
+	subscribeRoute(&called)
+	if err := Publish(context.Background(), nil, &AccountClosedEvent{BaseDomainEvent: &BaseDomainEvent{Aggregate: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(called, []string{"interface", "embedded", "all"}) {
+		t.Fatalf("called:%v", called)
+	}
+	// A nil embedded pointer skips the embedded handler only.
+	called = nil
+	if err := Publish(context.Background(), nil, &AccountClosedEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(called, []string{"interface", "all"}) {
+		t.Fatalf("called:%v", called)
+	}
+}
+
+func TestRouteDefaultExecutor(t *testing.T) {
+	isolateHub(t)
+	var called []string
+	subscribeRoute(&called)
+	hub.defaultExecutor = &recordExecutor{name: "default", called: &called}
+
+	// The wildcard subscriber is not a subscriber of the type.
+	if err := Publish(context.Background(), nil, &AccountFrozenEvent{}, WithMustHaveSubscriber()); err == nil {
+		t.Fatal("expected error")
+	}
+	called = nil
+	if err := Publish(context.Background(), nil, &AccountFrozenEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(called, []string{"all", "default"}) {
+		t.Fatalf("called:%v", called)
+	}
+
+	// Nor is the interface subscriber.
+	called = nil
+	if err := Publish(context.Background(), nil, &AccountAuditedEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(called, []string{"interface", "all", "default"}) {
+		t.Fatalf("called:%v", called)
+	}
+}
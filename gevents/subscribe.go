@@ -0,0 +1,100 @@
+package gevents
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// funcExecutor adapts a typed handler to an Executor, it is not provided to
+// ginjects as several handlers of the same event share its type.
+type funcExecutor[E any] struct {
+	fn func(ctx context.Context, e *E) error
+}
+
+func (f *funcExecutor[E]) Execute(ctx context.Context, event interface{}) error {
+	switch e := event.(type) {
+	case *E:
+		return f.fn(ctx, e)
+	case E:
+		return f.fn(ctx, &e)
+	}
+	if v, embedded := embeddedValue(event, f.Types()[0].Elem()); embedded {
+		// The embedded pointer is nil, the event is not of the handler.
+		if !v.IsValid() {
+			return nil
+		}
+		return f.fn(ctx, v.Interface().(*E))
+	}
+	return fmt.Errorf("gevents: event type `%T`, expected `%s`", event, f.Types()[0])
+}
+
+func (f *funcExecutor[E]) Types() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf((*E)(nil))}
+}
+
+// Subscribe 注册类型为 E 的事件的处理函数，E 为 Struct 类型，发布 E 或者 *E 类型的事件时调用
+// 发布嵌入了 E（或者 *E）的事件时也调用，参数为事件中嵌入的 E，嵌入的 *E 为 nil 时不调用
+// 与 Register 的事件处理器一起按照优先级执行，处理函数不会注入到 ginjects
+func Subscribe[E any](fn func(ctx context.Context, e *E) error, opts ...RegisterOption) {
+	if typ := reflect.TypeOf((*E)(nil)).Elem(); typ.Kind() == reflect.Ptr {
+		panic(fmt.Sprintf("gevents: subscribe event type `%s` must not be a pointer", typ))
+	}
+	if fn == nil {
+		panic("gevents: subscribe handler must not be nil")
+	}
+	ext := newExecutorExt(&funcExecutor[E]{fn: fn}, opts...)
+	ext.match = matchEmbedded
+	hub.register(ext)
+}
+
+// interfaceExecutor adapts a handler of the events implementing I.
+type interfaceExecutor[I any] struct {
+	fn func(ctx context.Context, e I) error
+}
+
+func (f *interfaceExecutor[I]) Execute(ctx context.Context, event interface{}) error {
+	if e, ok := event.(I); ok {
+		return f.fn(ctx, e)
+	}
+	// The event is a value and the pointer implements I.
+	v := reflect.New(reflect.TypeOf(event))
+	v.Elem().Set(reflect.ValueOf(event))
+	if e, ok := v.Interface().(I); ok {
+		return f.fn(ctx, e)
+	}
+	return fmt.Errorf("gevents: event type `%T`, expected `%s`", event, f.Types()[0])
+}
+
+func (f *interfaceExecutor[I]) Types() []reflect.Type {
+	return []reflect.Type{reflect.TypeOf((*I)(nil)).Elem()}
+}
+
+// SubscribeAs 注册实现了 interface I 的事件的处理函数，I 为 interface 类型
+// 事件或者事件的指针实现了 I 时调用，与 Subscribe 的处理函数一起按照优先级执行
+// 不作为事件类型的订阅者：事件没有其他的处理器时仍然调用默认处理器，WithMustHaveSubscriber 仍然返回错误
+func SubscribeAs[I any](fn func(ctx context.Context, e I) error, opts ...RegisterOption) {
+	if typ := reflect.TypeOf((*I)(nil)).Elem(); typ.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("gevents: subscribe event type `%s` must be an interface", typ))
+	}
+	if fn == nil {
+		panic("gevents: subscribe handler must not be nil")
+	}
+	ext := newExecutorExt(&interfaceExecutor[I]{fn: fn}, opts...)
+	ext.match = matchInterface
+	hub.register(ext)
+}
+
+// SubscribeAll 注册全部事件的处理函数，与其他的处理函数一起按照优先级执行，同 SubscribeAs 不作为事件类型的订阅者
+func SubscribeAll(fn func(ctx context.Context, e interface{}) error, opts ...RegisterOption) {
+	SubscribeAs(fn, opts...)
+}
+
+// Publish 发布类型为 E 的事件
+// @param publisher Publisher "事件发布器，为 nil 时使用 DefaultPublisher"
+func Publish[E any](ctx context.Context, publisher Publisher, event *E, opts ...PublishOption) error {
+	if publisher == nil {
+		publisher = &DefaultPublisher{}
+	}
+	return publisher.Publish(ctx, event, opts...)
+}
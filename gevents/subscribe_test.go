@@ -0,0 +1,58 @@
+package gevents
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type OrderPaidEvent struct {
+	Id     int
+	Amount int
+}
+
+type OrderRefundEvent struct {
+	Id int
+}
+
+func TestSubscribe(t *testing.T) {
+	var handled []string
+	Subscribe(func(ctx context.Context, e *OrderPaidEvent) error {
+		handled = append(handled, "audit")
+		return nil
+	})
+	Subscribe(func(ctx context.Context, e *OrderPaidEvent) error {
+		handled = append(handled, "notify")
+		e.Amount = 0
+		return nil
+	}, WithRegisterPriority(1))
+
+	event := &OrderPaidEvent{Id: 1, Amount: 100}
+	if err := Publish(context.Background(), nil, event, WithMustHaveSubscriber()); err != nil {
+		t.Fatal(err)
+	}
+	if len(handled) != 2 || handled[0] != "notify" || handled[1] != "audit" {
+		t.Fatalf("handled:%v", handled)
+	}
+	if event.Amount != 0 {
+		t.Fatalf("amount:%d", event.Amount)
+	}
+
+	// Published by value.
+	if err := (&DefaultPublisher{}).Publish(context.Background(), OrderPaidEvent{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(handled) != 4 {
+		t.Fatalf("handled:%v", handled)
+	}
+}
+
+func TestSubscribeError(t *testing.T) {
+	Subscribe(func(ctx context.Context, e *OrderRefundEvent) error {
+		return errors.New("refund failed")
+	})
+	err := Publish(context.Background(), &DefaultPublisher{}, &OrderRefundEvent{Id: 1})
+	if err == nil || err.Error() != "refund failed" {
+		t.Fatalf("err:%v", err)
+	}
+}
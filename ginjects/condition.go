@@ -0,0 +1,229 @@
+package ginjects
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/erkesi/gobean/glogs"
+)
+
+// ProfilesEnv 没有调用 SetProfiles 时，从环境变量获取激活的 profile，多个 profile 之间用逗号分隔，如：GINJECTS_PROFILES=dev,local
+const ProfilesEnv = "GINJECTS_PROFILES"
+
+// Condition 注入的条件，Init 时按照注入的顺序判断，满足条件的实例（或构造函数）才会注入
+type Condition struct {
+	desc  string
+	match func(g *graph) bool
+}
+
+func (c Condition) String() string {
+	return c.desc
+}
+
+// WithProvideCondition 条件注入，满足所有的条件时才注入
+func WithProvideCondition(conditions ...Condition) ProvideOption {
+	return func(opt *provideOptions) {
+		opt.conditions = append(opt.conditions, conditions...)
+	}
+}
+
+// OnProfile 激活了任意一个 profile 时满足条件
+func OnProfile(profiles ...string) Condition {
+	return Condition{
+		desc: fmt.Sprintf("on profile %s", strings.Join(profiles, ", ")),
+		match: func(g *graph) bool {
+			for _, active := range g.activeProfiles() {
+				for _, profile := range profiles {
+					if active == profile {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	}
+}
+
+// OnEnv 存在环境变量时满足条件，values 不为空时环境变量的值需要为其中之一
+func OnEnv(name string, values ...string) Condition {
+	return Condition{
+		desc: fmt.Sprintf("on env %s", name),
+		match: func(g *graph) bool {
+			value, ok := os.LookupEnv(name)
+			if !ok || len(values) == 0 {
+				return ok
+			}
+			for _, v := range values {
+				if value == v {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// OnMissing 没有注入类型为 T（Struct 指针或 interface）的实例（不包括命名实例），也没有返回值为 T 的构造函数时满足条件
+// 条件按照注入的顺序判断，不考虑之后注入的条件实例（或构造函数），需要在它们之后注入
+func OnMissing[T any]() Condition {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	return Condition{
+		desc: fmt.Sprintf("on missing %s", typ),
+		match: func(g *graph) bool {
+			return !g.provides(typ)
+		},
+	}
+}
+
+// OnMissingName 没有注入命名的实例时满足条件
+func OnMissingName(name string) Condition {
+	return Condition{
+		desc: fmt.Sprintf("on missing name %s", name),
+		match: func(g *graph) bool {
+			return g.findNamed(name) == nil
+		},
+	}
+}
+
+// OnFunc 自定义条件
+// @param desc string "条件的描述，用于日志"
+func OnFunc(desc string, fn func() bool) Condition {
+	return Condition{
+		desc: desc,
+		match: func(g *graph) bool {
+			return fn()
+		},
+	}
+}
+
+// SetProfiles 设置激活的 profile，需要在 Init 之前调用，子容器没有设置时使用父容器的 profile
+func (c *Container) SetProfiles(profiles ...string) {
+	c.g.profiles = append([]string{}, profiles...)
+}
+
+// SetProfiles 设置默认容器激活的 profile
+func SetProfiles(profiles ...string) {
+	defaultContainer.SetProfiles(profiles...)
+}
+
+// A value or a constructor provided with conditions, it is provided to the
+// graph at Init if the conditions match.
+type conditional struct {
+	object      *object      // Optional
+	constructor *constructor // Optional
+	conditions  []Condition
+	resolved    bool // If true, the conditions were evaluated
+}
+
+// String representation suitable for human consumption.
+func (c *conditional) String() string {
+	if c.constructor != nil {
+		return c.constructor.String()
+	}
+	if c.object.name != "" {
+		return fmt.Sprintf(`"%T named %s"`, c.object.value, c.object.name)
+	}
+	return fmt.Sprintf(`"%T"`, c.object.value)
+}
+
+// provideValue provides the value, or holds it until Init if it has
+// conditions.
+func (g *graph) provideValue(opt *provideOptions, value interface{}, name string) error {
+	o := opt.object(value, name)
+	if len(opt.conditions) > 0 {
+		g.conditionals = append(g.conditionals, &conditional{object: o, conditions: opt.conditions})
+		return nil
+	}
+	return g.provide(o)
+}
+
+// resolveConditionals provides the values and constructors whose conditions
+// match in the order they were provided, so the conditions see the ones
+// provided before them.
+func (g *graph) resolveConditionals() error {
+	for _, c := range g.conditionals {
+		if c.resolved {
+			continue
+		}
+		c.resolved = true
+		if matched, condition := c.match(g); !matched {
+			if glogs.Log != nil {
+				glogs.Log.Debugf(context.TODO(), "ginjects: skipped %s, condition %s not matched", c, condition)
+			}
+			continue
+		}
+		if c.constructor != nil {
+			g.constructors = append(g.constructors, c.constructor)
+			continue
+		}
+		if err := g.provide(c.object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// provides reports whether the conditional provides an unnamed object
+// assignable to typ.
+func (c *conditional) provides(typ reflect.Type) bool {
+	if c.constructor != nil {
+		return c.constructor.provides(typ)
+	}
+	return c.object.name == "" && c.object.value != nil && reflect.TypeOf(c.object.value).AssignableTo(typ)
+}
+
+// removeConditionals removes the conditionals not yet resolved matching fn.
+func (g *graph) removeConditionals(fn func(c *conditional) bool) {
+	conditionals := g.conditionals[:0]
+	for _, c := range g.conditionals {
+		if c.resolved || !fn(c) {
+			conditionals = append(conditionals, c)
+		}
+	}
+	g.conditionals = conditionals
+}
+
+func (c *conditional) match(g *graph) (bool, Condition) {
+	for _, condition := range c.conditions {
+		if !condition.match(g) {
+			return false, condition
+		}
+	}
+	return true, Condition{}
+}
+
+// activeProfiles returns the profiles set on the graph or its parents, or
+// read from the environment.
+func (g *graph) activeProfiles() []string {
+	for cur := g; cur != nil; cur = cur.parent {
+		if cur.profiles != nil {
+			return cur.profiles
+		}
+	}
+	var profiles []string
+	for _, profile := range strings.Split(os.Getenv(ProfilesEnv), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// provides reports whether an unnamed object assignable to typ was provided
+// to the graph or its parents, or a constructor provides one.
+func (g *graph) provides(typ reflect.Type) bool {
+	for cur := g; cur != nil; cur = cur.parent {
+		if len(cur.assignable(typ)) > 0 {
+			return true
+		}
+		for _, c := range cur.constructors {
+			if !c.invoked && c.provides(typ) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package ginjects
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForConditionCache interface {
+	Name() string
+}
+
+type TypeForConditionRedis struct{}
+
+func (c *TypeForConditionRedis) Name() string {
+	return "redis"
+}
+
+type TypeForConditionMemory struct{}
+
+func (c *TypeForConditionMemory) Name() string {
+	return "memory"
+}
+
+type TypeForConditionService struct {
+	Cache TypeForConditionCache `inject:""`
+}
+
+func TestConditionOnMissing(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnMissing[TypeForConditionCache]()))
+	c.ProvideByValue(&TypeForConditionService{})
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "memory")
+
+	// Provided after the conditional, still seen at Init.
+	c = NewContainer()
+	c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnMissing[TypeForConditionCache]()))
+	c.ProvideByValue(&TypeForConditionService{})
+	c.ProvideByConstructor(func() *TypeForConditionRedis {
+		return &TypeForConditionRedis{}
+	})
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+	_, err := ObtainFrom[*TypeForConditionMemory](c)
+	ensure.True(t, err != nil)
+
+	// Provided by the parent.
+	parent := NewContainer()
+	parent.ProvideByValue(&TypeForConditionRedis{})
+	c = parent.NewChild()
+	c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnMissing[TypeForConditionCache]()))
+	c.ProvideByValue(&TypeForConditionService{})
+	ensure.Nil(t, parent.Init())
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+}
+
+func TestConditionOrder(t *testing.T) {
+	// The conditionals see the ones resolved before them.
+	c := NewContainer()
+	c.ProvideByConstructor(func() *TypeForConditionRedis {
+		return &TypeForConditionRedis{}
+	}, WithProvideCondition(OnFunc("redis enabled", func() bool { return true })))
+	c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnMissing[TypeForConditionCache]()))
+	c.ProvideByValue(&TypeForConditionService{})
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+
+	c = NewContainer()
+	c.ProvideByName("cache", &TypeForConditionRedis{}, WithProvideCondition(OnMissingName("cache")))
+	c.ProvideByName("cache", &TypeForConditionMemory{}, WithProvideCondition(OnMissingName("cache")))
+	ensure.Nil(t, c.Init())
+	cache, err := ObtainNamedFrom[TypeForConditionCache](c, "cache")
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, cache.Name(), "redis")
+}
+
+func TestConditionOnProfile(t *testing.T) {
+	provide := func(c *Container) {
+		c.ProvideByValue(&TypeForConditionRedis{}, WithProvideCondition(OnProfile("prod", "staging")))
+		c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnProfile("dev")))
+		c.ProvideByValue(&TypeForConditionService{})
+	}
+
+	c := NewContainer()
+	c.SetProfiles("prod")
+	provide(c)
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+
+	t.Setenv(ProfilesEnv, "local, dev")
+	c = NewContainer()
+	provide(c)
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "memory")
+
+	// The child uses the profiles of the parent.
+	parent := NewContainer()
+	parent.SetProfiles("staging")
+	c = parent.NewChild()
+	provide(c)
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+
+	// No profile matched.
+	c = NewContainer()
+	c.SetProfiles()
+	provide(c)
+	ensure.NotNil(t, c.Init())
+}
+
+// The example of the README.
+func TestConditionFallback(t *testing.T) {
+	provide := func(c *Container) {
+		c.ProvideByConstructor(func() *TypeForConditionRedis {
+			return &TypeForConditionRedis{}
+		}, WithProvideCondition(OnProfile("prod")))
+		c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnMissing[TypeForConditionCache]()))
+		c.ProvideByValue(&TypeForConditionService{})
+	}
+
+	c := NewContainer()
+	c.SetProfiles("prod")
+	provide(c)
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+
+	c = NewContainer()
+	c.SetProfiles("dev")
+	provide(c)
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "memory")
+}
+
+func TestConditionOnEnv(t *testing.T) {
+	t.Setenv("GINJECTS_TEST_CACHE", "redis")
+	c := NewContainer()
+	c.ProvideByValue(&TypeForConditionRedis{}, WithProvideCondition(OnEnv("GINJECTS_TEST_CACHE", "redis")))
+	c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(
+		OnEnv("GINJECTS_TEST_CACHE"),
+		OnMissing[TypeForConditionCache](),
+	))
+	c.ProvideByName("missing", &TypeForConditionMemory{}, WithProvideCondition(OnEnv("GINJECTS_TEST_MISSING")))
+	c.ProvideByValue(&TypeForConditionService{})
+	ensure.Nil(t, c.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](c).Cache.Name(), "redis")
+	_, err := ObtainNamedFrom[TypeForConditionCache](c, "missing")
+	ensure.True(t, err != nil)
+}
+
+func TestConditionCopyAndOverride(t *testing.T) {
+	c := NewContainer()
+	c.SetProfiles("dev")
+	c.ProvideByValue(&TypeForConditionMemory{}, WithProvideCondition(OnProfile("dev")))
+	c.ProvideByValue(&TypeForConditionService{})
+
+	copied := c.Copy()
+	ensure.Nil(t, copied.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](copied).Cache.Name(), "memory")
+
+	copied = c.Copy()
+	copied.OverrideByType(reflect.TypeOf((*TypeForConditionCache)(nil)).Elem(), &TypeForConditionRedis{})
+	ensure.Nil(t, copied.Init())
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForConditionService](copied).Cache.Name(), "redis")
+}
@@ -0,0 +1,216 @@
+package ginjects
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/erkesi/gobean/glogs"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// A constructor in the graph. Its parameters are resolved from the graph and
+// its results are provided back to the graph as unnamed objects.
+type constructor struct {
+	fn       reflect.Value
+	opt      *provideOptions // Applied to the results
+	invoking bool            // If true, the constructor is resolving its parameters
+	invoked  bool            // If true, the results were provided to the graph
+}
+
+// String representation suitable for human consumption.
+func (c *constructor) String() string {
+	return fmt.Sprintf(`"%s"`, c.fn.Type().String())
+}
+
+// hasError reports whether the last result of the constructor is an error.
+func (c *constructor) hasError() bool {
+	typ := c.fn.Type()
+	return typ.NumOut() > 0 && typ.Out(typ.NumOut()-1) == errorType
+}
+
+// results returns the types of the values provided by the constructor.
+func (c *constructor) results() []reflect.Type {
+	typ := c.fn.Type()
+	n := typ.NumOut()
+	if c.hasError() {
+		n--
+	}
+	types := make([]reflect.Type, 0, n)
+	for i := 0; i < n; i++ {
+		types = append(types, typ.Out(i))
+	}
+	return types
+}
+
+// provides reports whether a result of the constructor is assignable to typ.
+func (c *constructor) provides(typ reflect.Type) bool {
+	for _, result := range c.results() {
+		if result.AssignableTo(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func newConstructor(fn interface{}, opt *provideOptions) (*constructor, error) {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func || fnValue.IsNil() {
+		return nil, fmt.Errorf("ginjects: expected constructor to be a func but got type %T", fn)
+	}
+	c := &constructor{fn: fnValue, opt: opt}
+	typ := fnValue.Type()
+	if typ.IsVariadic() {
+		return nil, fmt.Errorf("ginjects: constructor %s must not be variadic", c)
+	}
+	results := c.results()
+	if len(results) == 0 {
+		return nil, fmt.Errorf("ginjects: constructor %s must return at least one value", c)
+	}
+	for i, result := range results {
+		if result == errorType {
+			return nil, fmt.Errorf("ginjects: constructor %s may only return error as its last result", c)
+		}
+		if !isStructPtr(result) && result.Kind() != reflect.Interface {
+			return nil, fmt.Errorf(
+				"ginjects: result %d of constructor %s must be a pointer to a struct or an interface but got type %s",
+				i, c, result,
+			)
+		}
+	}
+	return c, nil
+}
+
+// provideConstructor adds a constructor to the graph, it is invoked when the
+// graph is populated.
+func (g *graph) provideConstructor(fn interface{}, opt *provideOptions) error {
+	c, err := newConstructor(fn, opt)
+	if err != nil {
+		return err
+	}
+	if len(opt.conditions) > 0 {
+		g.conditionals = append(g.conditionals, &conditional{constructor: c, conditions: opt.conditions})
+	} else {
+		g.constructors = append(g.constructors, c)
+	}
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(), "ginjects: provided constructor %s", c)
+	}
+	return nil
+}
+
+// invokeConstructors invokes every constructor not yet invoked, resolving the
+// constructors they depend on first.
+func (g *graph) invokeConstructors() error {
+	for _, c := range g.constructors {
+		if err := g.invokeConstructor(c); err != nil {
+			if err := g.reportProblem(&problem{owner: c.fn.Type().String(), err: err}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *graph) invokeConstructor(c *constructor) error {
+	if c.invoked {
+		return nil
+	}
+	if c.invoking {
+		return fmt.Errorf("ginjects: constructor %s depends on its own result", c)
+	}
+	c.invoking = true
+	defer func() {
+		c.invoking = false
+	}()
+
+	typ := c.fn.Type()
+	args := make([]reflect.Value, 0, typ.NumIn())
+	deps := make([]*object, 0, typ.NumIn())
+	for i := 0; i < typ.NumIn(); i++ {
+		dep, err := g.resolveConstructorParam(c, i)
+		if err != nil {
+			if err := g.reportProblem(&problem{owner: c.fn.Type().String(), field: fmt.Sprintf("arg%d", i), err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		arg, err := g.decorate(dep, typ.In(i))
+		if err != nil {
+			return err
+		}
+		args = append(args, arg)
+		deps = append(deps, dep)
+	}
+
+	// Constructors are not invoked when validating, placeholders are provided
+	// for their results instead.
+	if g.dryRun {
+		c.invoked = true
+		return g.providePlaceholders(c, deps)
+	}
+
+	outs := c.fn.Call(args)
+	if c.hasError() {
+		if err, _ := outs[len(outs)-1].Interface().(error); err != nil {
+			return fmt.Errorf("ginjects: constructor %s failed: %w", c, err)
+		}
+		outs = outs[:len(outs)-1]
+	}
+	c.invoked = true
+
+	for i, out := range outs {
+		if isNilOrZero(out, out.Type()) {
+			return fmt.Errorf("ginjects: constructor %s returned nil for result %d", c, i)
+		}
+		o := c.opt.object(out.Interface(), "")
+		o.args = deps
+		o.constructor = c
+		if err := g.provide(o); err != nil {
+			return err
+		}
+		for _, dep := range deps {
+			o.addEdge(g, dep)
+		}
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(), "ginjects: constructed %s by constructor %s", o, c)
+		}
+	}
+	return nil
+}
+
+// resolveConstructorParam finds the object assignable to the i-th parameter of
+// the constructor, invoking the constructor providing it if necessary.
+func (g *graph) resolveConstructorParam(c *constructor, i int) (*object, error) {
+	paramType := c.fn.Type().In(i)
+	if !isStructPtr(paramType) && paramType.Kind() != reflect.Interface {
+		return nil, fmt.Errorf(
+			"ginjects: parameter %d of constructor %s must be a pointer to a struct or an interface but got type %s",
+			i, c, paramType,
+		)
+	}
+	for _, other := range g.constructors {
+		if other == c || other.invoked || !other.provides(paramType) {
+			continue
+		}
+		if err := g.invokeConstructor(other); err != nil {
+			return nil, err
+		}
+	}
+
+	found := g.findCandidates(paramType, "")
+	if len(found) > 1 {
+		return nil, fmt.Errorf(
+			"ginjects: found two assignable values for parameter %d of constructor %s. one type %s and another type %s",
+			i, c, found[0].reflectType, found[1].reflectType,
+		)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf(
+			"ginjects: found no assignable value for parameter %d (%s) of constructor %s",
+			i, paramType, c,
+		)
+	}
+	return found[0], nil
+}
@@ -0,0 +1,169 @@
+package ginjects
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForConstructorConfig struct {
+	DSN string
+}
+
+type TypeForConstructorDB struct {
+	Config *TypeForConstructorConfig
+}
+
+type TypeForConstructorRepo struct {
+	DB     *TypeForConstructorDB
+	Answer Answerable
+}
+
+type TypeForConstructorService struct {
+	Repo *TypeForConstructorRepo `inject:""`
+}
+
+func TestConstructor(t *testing.T) {
+	g := &graph{
+		refType2InjectFieldIndies: map[reflect.Type]map[int]struct{}{},
+	}
+	cfg := &TypeForConstructorConfig{DSN: "dsn"}
+	var s TypeForConstructorService
+	ensure.Nil(t, g.provide(&object{value: cfg}, &object{value: &s}, &object{value: &TypeAnswerStruct{answer: 42}}))
+	// the repo constructor is provided before the db constructor it depends on.
+	ensure.Nil(t, g.provideConstructor(func(db *TypeForConstructorDB, a Answerable) (*TypeForConstructorRepo, error) {
+		return &TypeForConstructorRepo{DB: db, Answer: a}, nil
+	}, apply()))
+	ensure.Nil(t, g.provideConstructor(func(cfg *TypeForConstructorConfig) *TypeForConstructorDB {
+		return &TypeForConstructorDB{Config: cfg}
+	}, apply()))
+	ensure.Nil(t, g.populate())
+
+	if s.Repo == nil {
+		t.Fatal("s.Repo is nil")
+	}
+	if s.Repo.DB.Config != cfg {
+		t.Fatal("s.Repo.DB.Config was not injected")
+	}
+	if s.Repo.Answer.Answer() != 42 {
+		t.Fatal("s.Repo.Answer was not injected")
+	}
+
+	var actual []string
+	for _, o := range g.objects() {
+		actual = append(actual, o.String())
+	}
+	ensure.DeepEqual(t, actual, []string{
+		"\"*ginjects.TypeForConstructorConfig\"",
+		"\"*ginjects.TypeAnswerStruct\"",
+		"\"*ginjects.TypeForConstructorDB\"",
+		"\"*ginjects.TypeForConstructorRepo\"",
+		"\"*ginjects.TypeForConstructorService\"",
+	})
+}
+
+func TestConstructorError(t *testing.T) {
+	g := &graph{
+		refType2InjectFieldIndies: map[reflect.Type]map[int]struct{}{},
+	}
+	ensure.Nil(t, g.provideConstructor(func() (*TypeForConstructorDB, error) {
+		return nil, errors.New("connection refused")
+	}, apply()))
+	err := g.populate()
+	ensure.NotNil(t, err)
+
+	const msg = "ginjects: constructor \"func() (*ginjects.TypeForConstructorDB, error)\" failed: connection refused"
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestConstructorMissingParam(t *testing.T) {
+	g := &graph{
+		refType2InjectFieldIndies: map[reflect.Type]map[int]struct{}{},
+	}
+	ensure.Nil(t, g.provideConstructor(func(cfg *TypeForConstructorConfig) *TypeForConstructorDB {
+		return &TypeForConstructorDB{Config: cfg}
+	}, apply()))
+	err := g.populate()
+	ensure.NotNil(t, err)
+
+	const msg = "ginjects: found no assignable value for parameter 0 (*ginjects.TypeForConstructorConfig) of constructor \"func(*ginjects.TypeForConstructorConfig) *ginjects.TypeForConstructorDB\""
+	if err.Error() != msg {
+		t.Fatalf("expected:\n%s\nactual:\n%s", msg, err.Error())
+	}
+}
+
+func TestConstructorCycle(t *testing.T) {
+	g := &graph{
+		refType2InjectFieldIndies: map[reflect.Type]map[int]struct{}{},
+	}
+	ensure.Nil(t, g.provideConstructor(func(*TypeForConstructorRepo) *TypeForConstructorDB {
+		return &TypeForConstructorDB{}
+	}, apply()))
+	ensure.Nil(t, g.provideConstructor(func(*TypeForConstructorDB) *TypeForConstructorRepo {
+		return &TypeForConstructorRepo{}
+	}, apply()))
+	err := g.populate()
+	ensure.NotNil(t, err)
+	if !strings.Contains(err.Error(), "depends on its own result") {
+		t.Fatalf("unexpected error %s", err)
+	}
+}
+
+func TestInvalidConstructor(t *testing.T) {
+	cases := []struct {
+		fn  interface{}
+		msg string
+	}{
+		{fn: 1, msg: "ginjects: expected constructor to be a func but got type int"},
+		{fn: func() {}, msg: "ginjects: constructor \"func()\" must return at least one value"},
+		{fn: func() int { return 0 }, msg: "ginjects: result 0 of constructor \"func() int\" must be a pointer to a struct or an interface but got type int"},
+		{fn: func() (error, *TypeAnswerStruct) { return nil, nil }, msg: "ginjects: constructor \"func() (error, *ginjects.TypeAnswerStruct)\" may only return error as its last result"},
+		{fn: func(...int) *TypeAnswerStruct { return nil }, msg: "ginjects: constructor \"func(...int) *ginjects.TypeAnswerStruct\" must not be variadic"},
+	}
+	for _, c := range cases {
+		_, err := newConstructor(c.fn, apply())
+		ensure.NotNil(t, err)
+		ensure.DeepEqual(t, err.Error(), c.msg)
+	}
+}
+
+type TypeForConstructorPool struct {
+	Config *TypeForConstructorConfig `inject:""`
+	inited bool
+}
+
+func (p *TypeForConstructorPool) Init() {
+	p.inited = true
+}
+
+type TypeForConstructorClient struct {
+	Pool       *TypeForConstructorPool
+	poolInited bool
+}
+
+func (c *TypeForConstructorClient) Init() {
+	c.poolInited = c.Pool.inited && c.Pool.Config != nil
+}
+
+// Constructors are invoked before the fields of their parameters are populated
+// and before the parameters are initialized, the results are initialized after
+// the parameters.
+func TestConstructorBeforeParamInit(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForConstructorConfig{DSN: "dsn"})
+	c.ProvideByValue(&TypeForConstructorPool{})
+	var populated, inited bool
+	c.ProvideByConstructor(func(pool *TypeForConstructorPool) *TypeForConstructorClient {
+		populated, inited = pool.Config != nil, pool.inited
+		return &TypeForConstructorClient{Pool: pool}
+	})
+	ensure.Nil(t, c.Init())
+	ensure.False(t, populated)
+	ensure.False(t, inited)
+	ensure.True(t, MustObtainFrom[*TypeForConstructorClient](c).poolInited)
+}
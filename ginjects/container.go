@@ -0,0 +1,280 @@
+package ginjects
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/erkesi/gobean/glogs"
+	"github.com/erkesi/gobean/grecovers"
+	"github.com/erkesi/gobean/gshares"
+)
+
+// Container 依赖注入容器，管理实例的注入、获取、初始化与销毁
+// 子容器（NewChild）可以获取父容器的实例，也可以注入同类型或同命名的实例覆盖父容器的实例
+type Container struct {
+	once    sync.Once
+	g       *graph
+	parent  *Container
+	initErr error
+
+	mu           sync.Mutex        // Guards the fields below, serializes Init, Replace and Close
+	inited       []*object         // The initialized objects in order, closed in reverse order
+	ready        bool              // If true, the objects were initialized and not yet closed
+	initDuration time.Duration     // The time spent initializing the objects
+	events       []*LifecycleEvent // The init and close of the objects in order
+}
+
+// NewContainer 创建一个独立的容器
+func NewContainer() *Container {
+	return &Container{g: newGraph(nil)}
+}
+
+// NewChild 创建子容器
+// 子容器 Init 时会先完成父容器的 Init，子容器 Close 时只销毁子容器的实例
+func (c *Container) NewChild() *Container {
+	return &Container{g: newGraph(c.g), parent: c}
+}
+
+// Init 完成依赖注入（包括调用构造函数）以及按照顺序完成实例的初始化，只执行一次
+// @return error 依赖注入、构造函数或实例初始化返回的错误
+func (c *Container) Init(opts ...InitOption) error {
+	return c.InitContext(context.Background(), opts...)
+}
+
+// InitContext 同 Init，ctx 传递给实例的 InitContext 方法
+// @return error 依赖注入的错误或实例初始化的错误（*ObjectError），初始化失败时已经初始化的实例会被销毁
+func (c *Container) InitContext(ctx context.Context, opts ...InitOption) error {
+	c.once.Do(func() {
+		if c.parent != nil {
+			if err := c.parent.InitContext(ctx, opts...); err != nil {
+				c.initErr = err
+				return
+			}
+		}
+		if err := c.g.populate(); err != nil {
+			c.initErr = err
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		start := time.Now()
+		if opt := applyInit(opts...); opt.parallel {
+			c.initErr = c.initObjectsParallel(ctx, opt.workers)
+		} else {
+			c.initErr = c.initObjects(ctx)
+		}
+		c.initDuration = time.Since(start)
+		c.ready = c.initErr == nil
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(), "ginjects: init %d inject objects cost %s", len(c.inited), c.initDuration)
+		}
+	})
+	return c.initErr
+}
+
+// Close 按照实例初始化的逆向顺序销毁实例
+func (c *Container) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, err := range c.closeObjects(context.Background()) {
+		if glogs.Log != nil {
+			glogs.Log.Errorf(context.TODO(), "%v", err)
+		}
+	}
+}
+
+// CloseContext 同 Close，ctx 传递给实例的 CloseContext 方法
+// 实例销毁失败、panic 或超时（WithProvideCloseTimeout）时继续销毁其余的实例；ctx 结束（如：超过截止时间）后不再等待，其余的实例不再销毁
+// @return error 全部销毁失败的实例的错误（Errors，元素为 *ObjectError）
+func (c *Container) CloseContext(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if errs := c.closeObjects(ctx); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// initObjects initializes the objects in order, on failure (or panic) the
+// objects already initialized are closed in reverse order.
+func (c *Container) initObjects(ctx context.Context) error {
+	for _, obj := range c.g.objects() {
+		if obj.initFn != nil {
+			if glogs.Log != nil {
+				glogs.Log.Debugf(context.TODO(), "ginjects: init inject object(%v)", obj)
+			}
+			start := time.Now()
+			err := grecovers.RecoverOfErr(func() error {
+				return obj.initFn(ctx)
+			})
+			c.record("init", obj, start, time.Since(start), err)
+			if err != nil {
+				initErr := &ObjectError{Object: obj.String(), Op: "init", Err: err}
+				if closeErrs := c.closeObjects(gshares.WithoutCancel(ctx)); len(closeErrs) > 0 {
+					return append(Errors{initErr}, closeErrs...)
+				}
+				return initErr
+			}
+		}
+		c.inited = append(c.inited, obj)
+	}
+	return nil
+}
+
+// closeObjects closes the initialized objects in reverse order, every object
+// is closed even if some of them fail or panic. Once ctx is done the objects
+// left are not closed and reported with the error of ctx.
+func (c *Container) closeObjects(ctx context.Context) Errors {
+	var errs Errors
+	// The private Lazy and Provider instances depend on the objects.
+	privates := c.g.takePrivates()
+	for i := len(privates) - 1; i >= 0; i-- {
+		privates[i].mu.Lock()
+		errs = append(errs, privates[i].closeObjects(ctx)...)
+		privates[i].mu.Unlock()
+	}
+	for i := len(c.inited) - 1; i >= 0; i-- {
+		obj := c.inited[i]
+		if obj.closeFn == nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			c.record("close", obj, time.Now(), 0, err)
+			errs = append(errs, &ObjectError{Object: obj.String(), Op: "close", Err: err})
+			continue
+		}
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(), "ginjects: close inject object(%v)", obj)
+		}
+		start := time.Now()
+		err := closeObject(ctx, obj)
+		c.record("close", obj, start, time.Since(start), err)
+		if err != nil {
+			errs = append(errs, &ObjectError{Object: obj.String(), Op: "close", Err: err})
+		}
+	}
+	c.inited = nil
+	c.ready = false
+	return errs
+}
+
+// closeObject closes the object recovering from panics. It stops waiting when
+// ctx is done or the close timeout of the object elapses, the close function
+// keeps running in the background.
+func closeObject(ctx context.Context, obj *object) error {
+	if obj.closeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, obj.closeTimeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		return grecovers.RecoverOfErr(func() error {
+			return obj.closeFn(ctx)
+		})
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- grecovers.RecoverOfErr(func() error {
+			return obj.closeFn(ctx)
+		})
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ProvideByName 通过命名注入实例
+// @param name string "命名"
+// @param value interface 实例
+func (c *Container) ProvideByName(name string, value interface{}, opts ...ProvideOption) {
+	if err := c.g.provideValue(apply(opts...), value, name); err != nil {
+		panic(err)
+	}
+}
+
+// ProvideByValue
+// @description 通过实例类型注入实例
+// @param value 实例
+func (c *Container) ProvideByValue(value interface{}, opts ...ProvideOption) {
+	if err := c.g.provideValue(apply(opts...), value, ""); err != nil {
+		panic(err)
+	}
+}
+
+// ProvideByConstructor
+// @description 通过构造函数注入实例，构造函数的参数从依赖中获取，返回值作为实例注入（Init 时调用）
+// 构造函数在依赖注入之前调用：参数的字段还没有注入，也没有初始化，构造函数只应该保存参数，在返回值的初始化方法中使用（返回值在参数之后初始化）
+// @param constructor 构造函数，如：func(db *DB, cfg *Config) (*Repo, error)
+func (c *Container) ProvideByConstructor(constructor interface{}, opts ...ProvideOption) {
+	if err := c.g.provideConstructor(constructor, apply(opts...)); err != nil {
+		panic(err)
+	}
+}
+
+// ObtainByName 通过命名获取实例，找不到时从父容器获取
+// @param name string "命名"
+// @return 指定名字的实例
+func (c *Container) ObtainByName(name string) interface{} {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	if obj := c.g.findNamed(name); obj != nil {
+		return obj.value
+	}
+	panic(fmt.Sprintf("ginjects: not found name `%s` instance", name))
+}
+
+// ObtainByType 通过值的类型（可以是interface或struct）获取实例，找不到时从父容器获取
+// @param value interface "值（类型可以是interface或struct）"
+// @return 匹配值的类型的实例
+func (c *Container) ObtainByType(value interface{}) interface{} {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		panic("ginjects: the value must be a reference pointer")
+	}
+	if typ.Kind() != reflect.Ptr {
+		panic("ginjects: the value must be a reference pointer")
+	}
+	elemTyp := typ.Elem()
+	switch elemTyp.Kind() {
+	case reflect.Interface:
+		found := c.g.findCandidates(elemTyp, "")
+		if len(found) == 0 {
+			panic(fmt.Sprintf("ginjects: not found type `%s` instance or implement type `%s`", elemTyp.String(), elemTyp.String()))
+		}
+		if len(found) > 1 {
+			assignableTypes := make([]string, 0, len(found))
+			for _, o := range found {
+				assignableTypes = append(assignableTypes, o.reflectType.String())
+			}
+			panic(fmt.Sprintf("ginjects: exist type `%v` instance or implement type `%s`", assignableTypes, elemTyp.String()))
+		}
+		return found[0].value
+	case reflect.Struct:
+		found := c.g.findAssignable(typ)
+		if len(found) == 0 {
+			panic(fmt.Sprintf("ginjects: not found type `%s` instance", typ.String()))
+		}
+		return found[0].value
+	default:
+		panic("ginjects: the value must be a reference pointer to struct or interface")
+	}
+}
+
+// PrintObjects 按照依赖注入的关系打印实例列表（不包括父容器的实例）
+func (c *Container) PrintObjects() []string {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	var r []string
+	for _, o := range c.g.objects() {
+		r = append(r, o.String())
+	}
+	return r
+}
@@ -0,0 +1,287 @@
+package ginjects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForContainerCounter struct {
+	inits  int
+	closes int
+}
+
+func (c *TypeForContainerCounter) Init() {
+	c.inits++
+}
+
+func (c *TypeForContainerCounter) Close() {
+	c.closes++
+}
+
+type TypeForContainer struct {
+	Counter *TypeForContainerCounter `inject:""`
+	Named   *TypeAnswerStruct        `inject:"answer"`
+	Answer  Answerable               `inject:""`
+}
+
+func TestContainerIsolation(t *testing.T) {
+	c1 := NewContainer()
+	c2 := NewContainer()
+	counter := &TypeForContainerCounter{}
+	c1.ProvideByValue(counter)
+	c2.ProvideByValue(counter)
+	ensure.Nil(t, c1.Init())
+	ensure.Nil(t, c2.Init())
+	ensure.Nil(t, c1.Init())
+	ensure.DeepEqual(t, counter.inits, 2)
+	ensure.DeepEqual(t, c1.ObtainByType(&TypeForContainerCounter{}), counter)
+}
+
+func TestContainerChild(t *testing.T) {
+	parent := NewContainer()
+	parentCounter := &TypeForContainerCounter{}
+	parentAnswer := &TypeAnswerStruct{answer: 1}
+	parent.ProvideByValue(parentCounter)
+	parent.ProvideByValue(parentAnswer)
+	parent.ProvideByName("answer", parentAnswer)
+
+	child := parent.NewChild()
+	childAnswer := &TypeAnswerStruct{answer: 2}
+	child.ProvideByName("answer", childAnswer)
+	var v TypeForContainer
+	child.ProvideByValue(&v)
+	ensure.Nil(t, child.Init())
+
+	// the parent is initialized by the child.
+	ensure.DeepEqual(t, parentCounter.inits, 1)
+	// inherited from the parent.
+	ensure.True(t, v.Counter == parentCounter)
+	ensure.True(t, v.Answer.(*TypeAnswerStruct) == parentAnswer)
+	// overridden by the child.
+	ensure.True(t, v.Named == childAnswer)
+	ensure.True(t, child.ObtainByName("answer").(*TypeAnswerStruct) == childAnswer)
+	ensure.True(t, parent.ObtainByName("answer").(*TypeAnswerStruct) == parentAnswer)
+	ensure.True(t, child.ObtainByType(&TypeForContainerCounter{}).(*TypeForContainerCounter) == parentCounter)
+	ensure.SameElements(t, child.PrintObjects(), []string{
+		"\"*ginjects.TypeAnswerStruct named answer\"",
+		"\"*ginjects.TypeForContainer\"",
+	})
+
+	child.Close()
+	ensure.DeepEqual(t, parentCounter.closes, 0)
+	parent.Close()
+	ensure.DeepEqual(t, parentCounter.closes, 1)
+}
+
+func TestContainerChildOverrideType(t *testing.T) {
+	parent := NewContainer()
+	parent.ProvideByValue(&TypeAnswerStruct{answer: 1})
+
+	child := parent.NewChild()
+	child.ProvideByValue(&TypeAnswerStruct{answer: 2})
+	var v TypeNestedStruct
+	child.ProvideByValue(&v)
+	ensure.Nil(t, child.Init())
+	ensure.DeepEqual(t, v.Answer(), 2)
+	ensure.DeepEqual(t, child.ObtainByType(&TypeAnswerStruct{}).(*TypeAnswerStruct).Answer(), 2)
+	ensure.DeepEqual(t, parent.ObtainByType(&TypeAnswerStruct{}).(*TypeAnswerStruct).Answer(), 1)
+}
+
+type TypeForLifecycleRecorder struct {
+	records []string
+}
+
+type TypeForLifecycleDB struct {
+	Recorder *TypeForLifecycleRecorder `inject:""`
+	closeErr error
+}
+
+func (d *TypeForLifecycleDB) InitContext(ctx context.Context) error {
+	d.Recorder.records = append(d.Recorder.records, "db.init")
+	return nil
+}
+
+func (d *TypeForLifecycleDB) CloseContext(ctx context.Context) error {
+	d.Recorder.records = append(d.Recorder.records, "db.close")
+	return d.closeErr
+}
+
+type TypeForLifecycleCache struct {
+	Recorder *TypeForLifecycleRecorder `inject:""`
+	DB       *TypeForLifecycleDB       `inject:""`
+}
+
+func (c *TypeForLifecycleCache) Init() {
+	c.Recorder.records = append(c.Recorder.records, "cache.init")
+}
+
+func (c *TypeForLifecycleCache) Close() {
+	c.Recorder.records = append(c.Recorder.records, "cache.close")
+}
+
+type TypeForLifecycleRepo struct {
+	Cache *TypeForLifecycleCache `inject:""`
+}
+
+func (r *TypeForLifecycleRepo) InitContext(ctx context.Context) error {
+	r.Cache.Recorder.records = append(r.Cache.Recorder.records, "repo.init")
+	return errors.New("connection refused")
+}
+
+func (r *TypeForLifecycleRepo) CloseContext(ctx context.Context) error {
+	r.Cache.Recorder.records = append(r.Cache.Recorder.records, "repo.close")
+	return nil
+}
+
+func TestContainerInitRollback(t *testing.T) {
+	c := NewContainer()
+	recorder := &TypeForLifecycleRecorder{}
+	c.ProvideByValue(recorder)
+	c.ProvideByValue(&TypeForLifecycleRepo{})
+	err := c.InitContext(context.Background())
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: init inject object(\"*ginjects.TypeForLifecycleRepo\") failed: connection refused")
+
+	var objErr *ObjectError
+	ensure.True(t, errors.As(err, &objErr))
+	ensure.DeepEqual(t, objErr.Object, "\"*ginjects.TypeForLifecycleRepo\"")
+	ensure.DeepEqual(t, recorder.records, []string{"db.init", "cache.init", "repo.init", "cache.close", "db.close"})
+
+	// already closed by the rollback.
+	c.Close()
+	ensure.DeepEqual(t, len(recorder.records), 5)
+}
+
+func TestContainerInitRollbackCloseError(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForLifecycleRecorder{})
+	c.ProvideByValue(&TypeForLifecycleDB{closeErr: errors.New("broken pipe")})
+	c.ProvideByValue(&TypeForLifecycleRepo{})
+	err := c.Init()
+	ensure.NotNil(t, err)
+
+	var errs Errors
+	ensure.True(t, errors.As(err, &errs))
+	ensure.DeepEqual(t, len(errs), 2)
+	ensure.DeepEqual(t, errs[1].Error(), "ginjects: close inject object(\"*ginjects.TypeForLifecycleDB\") failed: broken pipe")
+}
+
+type TypeForInitPanic struct {
+	DB *TypeForLifecycleDB `inject:""`
+}
+
+func (p *TypeForInitPanic) Init() {
+	panic("boom")
+}
+
+func TestContainerInitPanic(t *testing.T) {
+	c := NewContainer()
+	recorder := &TypeForLifecycleRecorder{}
+	c.ProvideByValue(recorder)
+	c.ProvideByValue(&TypeForInitPanic{})
+	err := c.Init()
+	var objErr *ObjectError
+	ensure.True(t, errors.As(err, &objErr))
+	ensure.DeepEqual(t, objErr.Op, "init")
+	ensure.StringContains(t, err.Error(), "boom")
+	ensure.DeepEqual(t, recorder.records, []string{"db.init", "db.close"})
+	// The error is kept for the next calls.
+	ensure.True(t, c.Init() == err)
+	ensure.False(t, c.Health(context.Background()).Ready)
+}
+
+type TypeForCloseHanging struct {
+	Recorder *TypeForLifecycleRecorder `inject:""`
+	release  chan struct{}
+}
+
+func (h *TypeForCloseHanging) CloseContext(ctx context.Context) error {
+	<-h.release
+	return nil
+}
+
+type TypeForClosePanic struct {
+	Hanging *TypeForCloseHanging `inject:""`
+}
+
+func (p *TypeForClosePanic) Close() {
+	panic("closed twice")
+}
+
+type TypeForCloseLast struct {
+	Panic *TypeForClosePanic `inject:""`
+}
+
+func (l *TypeForCloseLast) CloseContext(ctx context.Context) error {
+	l.Panic.Hanging.Recorder.records = append(l.Panic.Hanging.Recorder.records, "last.close")
+	return nil
+}
+
+func TestContainerCloseContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := NewContainer()
+	recorder := &TypeForLifecycleRecorder{}
+	c.ProvideByValue(recorder)
+	c.ProvideByValue(&TypeForCloseHanging{release: release}, WithProvideCloseTimeout(10*time.Millisecond))
+	c.ProvideByValue(&TypeForClosePanic{})
+	c.ProvideByValue(&TypeForCloseLast{})
+	ensure.Nil(t, c.Init())
+
+	err := c.CloseContext(context.Background())
+	var errs Errors
+	ensure.True(t, errors.As(err, &errs))
+	ensure.DeepEqual(t, len(errs), 2)
+	ensure.StringContains(t, errs[0].Error(), "ginjects: close inject object(\"*ginjects.TypeForClosePanic\") failed: ")
+	ensure.StringContains(t, errs[0].Error(), "closed twice")
+	ensure.True(t, errors.Is(errs[1], context.DeadlineExceeded))
+	ensure.DeepEqual(t, recorder.records, []string{"last.close"})
+
+	// already closed.
+	ensure.Nil(t, c.CloseContext(context.Background()))
+}
+
+func TestContainerCloseContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := NewContainer()
+	c.ProvideByValue(&TypeForLifecycleRecorder{})
+	c.ProvideByValue(&TypeForCloseHanging{release: release})
+	counter := &TypeForContainerCounter{}
+	c.ProvideByValue(counter, WithProvidePriorityTop1())
+	ensure.Nil(t, c.Init())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := c.CloseContext(ctx)
+	var errs Errors
+	ensure.True(t, errors.As(err, &errs))
+	ensure.DeepEqual(t, len(errs), 2)
+	var objErr *ObjectError
+	ensure.True(t, errors.As(errs[1], &objErr))
+	ensure.DeepEqual(t, objErr.Object, "\"*ginjects.TypeForContainerCounter\"")
+	ensure.True(t, errors.Is(objErr, context.DeadlineExceeded))
+	// not closed after the deadline.
+	ensure.DeepEqual(t, counter.closes, 0)
+}
+
+func TestContainerPrimaryAndQualifier(t *testing.T) {
+	c := NewContainer()
+	a := &TypeForCollectionHandlerA{}
+	b := &TypeForCollectionHandlerB{}
+	var v TypeForQualifier
+	c.ProvideByValue(&v)
+	c.ProvideByValue(a, WithProvidePrimary())
+	c.ProvideByValue(b, WithProvideQualifier("b", "mysql"))
+	ensure.Nil(t, c.Init())
+	ensure.True(t, v.Handler == a)
+	ensure.True(t, v.Qualified == b)
+
+	var handler TypeForCollectionHandler
+	ensure.True(t, c.ObtainByType(&handler) == a)
+	ensure.DeepEqual(t, c.ExportGraph().Nodes[2].Qualifiers, []string{"b", "mysql"})
+}
@@ -0,0 +1,164 @@
+package ginjects
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/erkesi/gobean/glogs"
+)
+
+// A decorator in the graph. It wraps the objects injected into the fields,
+// constructor parameters and collection elements of its type.
+type decorator struct {
+	fn  reflect.Value
+	typ reflect.Type // The type of the parameter and of the first result
+}
+
+// String representation suitable for human consumption.
+func (d *decorator) String() string {
+	return fmt.Sprintf(`"%s"`, d.fn.Type().String())
+}
+
+// hasError reports whether the decorator returns an error.
+func (d *decorator) hasError() bool {
+	return d.fn.Type().NumOut() == 2
+}
+
+func newDecorator(fn interface{}) (*decorator, error) {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func || fnValue.IsNil() {
+		return nil, fmt.Errorf("ginjects: expected decorator to be a func but got type %T", fn)
+	}
+	typ := fnValue.Type()
+	d := &decorator{fn: fnValue}
+	if typ.NumIn() != 1 || typ.IsVariadic() {
+		return nil, fmt.Errorf("ginjects: decorator %s must have exactly one parameter", d)
+	}
+	d.typ = typ.In(0)
+	if !isStructPtr(d.typ) && d.typ.Kind() != reflect.Interface {
+		return nil, fmt.Errorf(
+			"ginjects: parameter of decorator %s must be a pointer to a struct or an interface but got type %s",
+			d, d.typ,
+		)
+	}
+	switch {
+	case typ.NumOut() == 1 && typ.Out(0) == d.typ:
+	case typ.NumOut() == 2 && typ.Out(0) == d.typ && typ.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("ginjects: decorator %s must return %s or (%s, error)", d, d.typ, d.typ)
+	}
+	return d, nil
+}
+
+// provideDecorator adds a decorator to the graph, it is applied when the
+// objects are injected.
+func (g *graph) provideDecorator(fn interface{}) error {
+	d, err := newDecorator(fn)
+	if err != nil {
+		return err
+	}
+	g.decorators = append(g.decorators, d)
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(), "ginjects: provided decorator %s", d)
+	}
+	return nil
+}
+
+// The object wrapped for the dependents of a type.
+type decoratedKey struct {
+	object *object
+	typ    reflect.Type
+}
+
+// The wrapped value of a version of the object, the values wrapped before a
+// Replace are not reused.
+type decoratedValue struct {
+	version int
+	value   reflect.Value
+}
+
+// decorate returns the value of the object to inject into a dependent of type
+// typ, wrapped by the decorators of typ of the parent graphs and then of this
+// graph in the order they were provided. The wrapped value is shared by the
+// dependents of the same type.
+func (g *graph) decorate(o *object, typ reflect.Type) (reflect.Value, error) {
+	var decorators []*decorator
+	for cur := g; cur != nil; cur = cur.parent {
+		for i := len(cur.decorators) - 1; i >= 0; i-- {
+			if cur.decorators[i].typ == typ {
+				decorators = append(decorators, cur.decorators[i])
+			}
+		}
+	}
+	valueMu.RLock()
+	value, version := o.reflectValue, o.version
+	valueMu.RUnlock()
+	// Decorators are not invoked when validating.
+	if len(decorators) == 0 || g.dryRun {
+		return value, nil
+	}
+
+	g.decoratedMu.Lock()
+	defer g.decoratedMu.Unlock()
+	key := decoratedKey{object: o, typ: typ}
+	if decorated, ok := g.decorated[key]; ok && decorated.version == version {
+		return decorated.value, nil
+	}
+	for i := len(decorators) - 1; i >= 0; i-- {
+		d := decorators[i]
+		outs := d.fn.Call([]reflect.Value{value})
+		if d.hasError() {
+			if err, _ := outs[1].Interface().(error); err != nil {
+				return value, fmt.Errorf("ginjects: decorator %s of %s failed: %w", d, o, err)
+			}
+		}
+		if isNilOrZero(outs[0], outs[0].Type()) {
+			return value, fmt.Errorf("ginjects: decorator %s returned nil for %s", d, o)
+		}
+		value = outs[0]
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(), "ginjects: decorated %s by decorator %s", o, d)
+		}
+	}
+	if g.decorated == nil {
+		g.decorated = make(map[decoratedKey]decoratedValue)
+	}
+	g.decorated[key] = decoratedValue{version: version, value: value}
+	return value, nil
+}
+
+// forgetDecorated drops the wrapped values of the object replaced by Replace.
+func (g *graph) forgetDecorated(o *object) {
+	g.decoratedMu.Lock()
+	defer g.decoratedMu.Unlock()
+	for key := range g.decorated {
+		if key.object == o {
+			delete(g.decorated, key)
+		}
+	}
+}
+
+// decorateValue is like decorate for the dependents resolving the value at
+// runtime.
+func (g *graph) decorateValue(o *object, typ reflect.Type) (interface{}, error) {
+	value, err := g.decorate(o, typ)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// ProvideDecorator 注入装饰器，实例注入到类型为 T 的字段、构造函数参数、集合元素之前，使用装饰器的返回值代替实例
+// 同一个实例只装饰一次，多个装饰器按照注入的顺序装饰（父容器的装饰器先装饰），ObtainByType 等获取的仍然是原来的实例
+// @param decorator 装饰器，签名为 func(T) T 或者 func(T) (T, error)，T 为 Struct 指针或 interface，如：func(repo Repo) Repo
+func (c *Container) ProvideDecorator(decorator interface{}) {
+	if err := c.g.provideDecorator(decorator); err != nil {
+		panic(err)
+	}
+}
+
+// ProvideDecorator 向默认容器注入装饰器
+func ProvideDecorator(decorator interface{}) {
+	defaultContainer.ProvideDecorator(decorator)
+}
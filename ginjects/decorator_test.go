@@ -0,0 +1,107 @@
+package ginjects
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForDecoratorLogging struct {
+	TypeForCollectionHandler
+	prefix string
+}
+
+func (h *TypeForDecoratorLogging) Handle() string {
+	return h.prefix + "(" + h.TypeForCollectionHandler.Handle() + ")"
+}
+
+func decorateForTest(prefix string, decorations *int) func(TypeForCollectionHandler) TypeForCollectionHandler {
+	return func(h TypeForCollectionHandler) TypeForCollectionHandler {
+		*decorations++
+		return &TypeForDecoratorLogging{TypeForCollectionHandler: h, prefix: prefix}
+	}
+}
+
+type TypeForDecoratorService struct {
+	Handler  TypeForCollectionHandler   `inject:""`
+	Handlers []TypeForCollectionHandler `inject:""`
+	A        *TypeForCollectionHandlerA `inject:""`
+}
+
+type TypeForDecoratorRepo struct {
+	handler TypeForCollectionHandler
+}
+
+func TestDecorator(t *testing.T) {
+	c := NewContainer()
+	var decorations int
+	a := &TypeForCollectionHandlerA{}
+	c.ProvideByValue(a)
+	c.ProvideDecorator(decorateForTest("log", &decorations))
+	c.ProvideDecorator(decorateForTest("metrics", &decorations))
+	var v TypeForDecoratorService
+	c.ProvideByValue(&v)
+	c.ProvideByConstructor(func(h TypeForCollectionHandler) *TypeForDecoratorRepo {
+		return &TypeForDecoratorRepo{handler: h}
+	})
+	ensure.Nil(t, c.Init())
+
+	ensure.DeepEqual(t, v.Handler.Handle(), "metrics(log(a))")
+	ensure.DeepEqual(t, len(v.Handlers), 1)
+	ensure.DeepEqual(t, v.Handlers[0].Handle(), "metrics(log(a))")
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForDecoratorRepo](c).handler.Handle(), "metrics(log(a))")
+	// Only the dependents of the decorated type are decorated.
+	ensure.True(t, v.A == a)
+	ensure.True(t, c.ObtainByType(&TypeForCollectionHandlerA{}) == a)
+	// Decorated once, the wrapper is shared by the dependents.
+	ensure.True(t, v.Handler == v.Handlers[0])
+	ensure.DeepEqual(t, decorations, 2)
+}
+
+func TestDecoratorLazyAndChild(t *testing.T) {
+	parent := NewContainer()
+	var decorations int
+	parent.ProvideByValue(&TypeForCollectionHandlerB{})
+	parent.ProvideDecorator(decorateForTest("parent", &decorations))
+
+	child := parent.NewChild()
+	child.ProvideDecorator(decorateForTest("child", &decorations))
+	var v struct {
+		Lazy Lazy[TypeForCollectionHandler] `inject:""`
+	}
+	child.ProvideByValue(&v)
+	ensure.Nil(t, child.Init())
+	ensure.DeepEqual(t, decorations, 0)
+	ensure.DeepEqual(t, v.Lazy.MustGet().Handle(), "child(parent(b))")
+	ensure.True(t, v.Lazy.MustGet() == v.Lazy.MustGet())
+	ensure.DeepEqual(t, decorations, 2)
+}
+
+func TestDecoratorError(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForCollectionHandlerA{})
+	c.ProvideDecorator(func(h TypeForCollectionHandler) (TypeForCollectionHandler, error) {
+		return nil, errors.New("metrics unavailable")
+	})
+	var v struct {
+		Handler TypeForCollectionHandler `inject:""`
+	}
+	c.ProvideByValue(&v)
+	err := c.Init()
+	ensure.NotNil(t, err)
+	ensure.StringContains(t, err.Error(), "metrics unavailable")
+}
+
+func TestDecoratorInvalid(t *testing.T) {
+	for _, fn := range []interface{}{
+		nil,
+		func() TypeForCollectionHandler { return nil },
+		func(h TypeForCollectionHandler) {},
+		func(h TypeForCollectionHandler) *TypeForCollectionHandlerA { return nil },
+		func(s string) string { return s },
+	} {
+		c := NewContainer()
+		ensure.NotNil(t, c.g.provideDecorator(fn))
+	}
+}
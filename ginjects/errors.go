@@ -0,0 +1,36 @@
+package ginjects
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObjectError 实例的生命周期方法（初始化、销毁）返回的错误
+type ObjectError struct {
+	Object string // 实例的描述，如："*pkg.DB named db"
+	Op     string // 生命周期方法：init、close
+	Err    error
+}
+
+func (e *ObjectError) Error() string {
+	return fmt.Sprintf("ginjects: %s inject object(%s) failed: %v", e.Op, e.Object, e.Err)
+}
+
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// Errors 多个错误的集合，如：初始化失败的错误以及回滚时销毁实例的错误
+type Errors []error
+
+func (es Errors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, err := range es {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (es Errors) Unwrap() []error {
+	return es
+}
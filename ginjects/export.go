@@ -0,0 +1,160 @@
+package ginjects
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ObjectGraph 实例的依赖关系图，可以导出为 Graphviz DOT、Mermaid 以及 JSON 格式
+type ObjectGraph struct {
+	Nodes []*ObjectNode `json:"nodes"`
+	Edges []*ObjectEdge `json:"edges"`
+}
+
+// ObjectNode 实例
+type ObjectNode struct {
+	ID          int      `json:"id"`
+	Type        string   `json:"type"`
+	Name        string   `json:"name,omitempty"`
+	Priority    int      `json:"priority"`
+	Private     bool     `json:"private,omitempty"`     // 私有实例（inject:"private"）
+	Created     bool     `json:"created,omitempty"`     // 由 ginjects 创建的实例
+	Embedded    bool     `json:"embedded,omitempty"`    // 内联的 Struct（inject:"inline"）
+	Constructed bool     `json:"constructed,omitempty"` // 构造函数返回的实例
+	Primary     bool     `json:"primary,omitempty"`     // 优先使用的实例（WithProvidePrimary）
+	Qualifiers  []string `json:"qualifiers,omitempty"`  // 实例的限定符（WithProvideQualifier）
+}
+
+// ObjectEdge 实例（From）的字段（Field）注入了依赖的实例（To）
+// 构造函数返回的实例，Field 为构造函数的参数，如：arg0
+type ObjectEdge struct {
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Field string `json:"field"`
+}
+
+// ExportGraph 导出实例的依赖关系图（不包括父容器的实例），需要在 Init 之后调用
+func (c *Container) ExportGraph() *ObjectGraph {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	return c.g.export()
+}
+
+// ExportGraph 导出实例的依赖关系图，需要在 Init 之后调用
+func ExportGraph() *ObjectGraph {
+	return defaultContainer.ExportGraph()
+}
+
+func (g *graph) export() *ObjectGraph {
+	indexes := make([]int, 0, len(g.index2Object))
+	for index := range g.index2Object {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	og := &ObjectGraph{Nodes: make([]*ObjectNode, 0, len(indexes))}
+	for _, index := range indexes {
+		o := g.index2Object[index]
+		og.Nodes = append(og.Nodes, &ObjectNode{
+			ID:          o.index,
+			Type:        o.reflectType.String(),
+			Name:        o.name,
+			Priority:    o.priority,
+			Private:     o.private,
+			Created:     o.created,
+			Embedded:    o.embedded,
+			Constructed: o.args != nil,
+			Primary:     o.primary,
+			Qualifiers:  o.qualifiers,
+		})
+
+		fieldNames := make([]string, 0, len(o.fields))
+		for fieldName := range o.fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+		for _, fieldName := range fieldNames {
+			g.exportEdge(og, o, o.fields[fieldName], fieldName)
+		}
+		for i, arg := range o.args {
+			g.exportEdge(og, o, arg, fmt.Sprintf("arg%d", i))
+		}
+	}
+	return og
+}
+
+// exportEdge adds the edge unless the dependency belongs to a parent graph.
+func (g *graph) exportEdge(og *ObjectGraph, o, dep *object, field string) {
+	if g.index2Object[dep.index] != dep {
+		return
+	}
+	og.Edges = append(og.Edges, &ObjectEdge{From: o.index, To: dep.index, Field: field})
+}
+
+func (n *ObjectNode) labels() []string {
+	labels := []string{n.Type}
+	if n.Name != "" {
+		labels = append(labels, "named "+n.Name)
+	}
+	if n.Priority != 0 {
+		labels = append(labels, "priority "+strconv.Itoa(n.Priority))
+	}
+	if len(n.Qualifiers) > 0 {
+		labels = append(labels, "qualifier "+strings.Join(n.Qualifiers, ", "))
+	}
+	var flags []string
+	for _, flag := range []struct {
+		name string
+		on   bool
+	}{{"private", n.Private}, {"created", n.Created}, {"embedded", n.Embedded}, {"constructed", n.Constructed}, {"primary", n.Primary}} {
+		if flag.on {
+			flags = append(flags, flag.name)
+		}
+	}
+	if len(flags) > 0 {
+		labels = append(labels, "("+strings.Join(flags, ", ")+")")
+	}
+	return labels
+}
+
+// DOT 导出为 Graphviz DOT 格式，边由实例指向其依赖的实例
+func (og *ObjectGraph) DOT() string {
+	var builder strings.Builder
+	builder.WriteString("digraph ginjects {\n")
+	builder.WriteString("  node [shape=box];\n")
+	for _, n := range og.Nodes {
+		builder.WriteString(fmt.Sprintf("  n%d [label=%s];\n", n.ID, strconv.Quote(strings.Join(n.labels(), "\n"))))
+	}
+	for _, e := range og.Edges {
+		builder.WriteString(fmt.Sprintf("  n%d -> n%d [label=%s];\n", e.From, e.To, strconv.Quote(e.Field)))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// Mermaid 导出为 Mermaid flowchart 格式，边由实例指向其依赖的实例
+func (og *ObjectGraph) Mermaid() string {
+	var builder strings.Builder
+	builder.WriteString("flowchart TD\n")
+	for _, n := range og.Nodes {
+		builder.WriteString(fmt.Sprintf("  n%d[\"%s\"]\n", n.ID, mermaidEscape(strings.Join(n.labels(), "<br/>"))))
+	}
+	for _, e := range og.Edges {
+		builder.WriteString(fmt.Sprintf("  n%d -->|\"%s\"| n%d\n", e.From, mermaidEscape(e.Field), e.To))
+	}
+	return builder.String()
+}
+
+// JSON 导出为 JSON 格式
+func (og *ObjectGraph) JSON() ([]byte, error) {
+	return json.MarshalIndent(og, "", "  ")
+}
+
+var mermaidReplacer = strings.NewReplacer(`"`, "#quot;", "<br/>", "<br/>", "<", "#lt;", ">", "#gt;")
+
+func mermaidEscape(s string) string {
+	return mermaidReplacer.Replace(s)
+}
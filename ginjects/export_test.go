@@ -0,0 +1,105 @@
+package ginjects
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForExportRepo struct {
+	A *TypeAnswerStruct `inject:""`
+}
+
+type TypeForExport struct {
+	Named   *TypeAnswerStruct  `inject:"answer"`
+	Private *TypeAnswerStruct  `inject:"private"`
+	Repo    *TypeForExportRepo `inject:""`
+}
+
+func newExportContainer(t *testing.T) *Container {
+	c := NewContainer()
+	c.ProvideByName("answer", &TypeAnswerStruct{}, WithProvidePriority(10))
+	c.ProvideByValue(&TypeForExport{})
+	c.ProvideByConstructor(func(a *TypeAnswerStruct) *TypeForExportRepo {
+		return &TypeForExportRepo{A: a}
+	})
+	c.ProvideByValue(&TypeAnswerStruct{})
+	ensure.Nil(t, c.Init())
+	return c
+}
+
+func TestExportGraph(t *testing.T) {
+	og := newExportContainer(t).ExportGraph()
+	ensure.DeepEqual(t, og.Nodes, []*ObjectNode{
+		{ID: 1, Type: "*ginjects.TypeAnswerStruct", Name: "answer", Priority: 10},
+		{ID: 2, Type: "*ginjects.TypeForExport"},
+		{ID: 3, Type: "*ginjects.TypeAnswerStruct"},
+		{ID: 4, Type: "*ginjects.TypeForExportRepo", Constructed: true},
+		{ID: 5, Type: "*ginjects.TypeAnswerStruct", Private: true, Created: true},
+	})
+	ensure.DeepEqual(t, og.Edges, []*ObjectEdge{
+		{From: 2, To: 1, Field: "Named"},
+		{From: 2, To: 5, Field: "Private"},
+		{From: 2, To: 4, Field: "Repo"},
+		{From: 4, To: 3, Field: "arg0"},
+	})
+}
+
+func TestExportGraphFormats(t *testing.T) {
+	og := newExportContainer(t).ExportGraph()
+
+	ensure.DeepEqual(t, og.DOT(), `digraph ginjects {
+  node [shape=box];
+  n1 [label="*ginjects.TypeAnswerStruct\nnamed answer\npriority 10"];
+  n2 [label="*ginjects.TypeForExport"];
+  n3 [label="*ginjects.TypeAnswerStruct"];
+  n4 [label="*ginjects.TypeForExportRepo\n(constructed)"];
+  n5 [label="*ginjects.TypeAnswerStruct\n(private, created)"];
+  n2 -> n1 [label="Named"];
+  n2 -> n5 [label="Private"];
+  n2 -> n4 [label="Repo"];
+  n4 -> n3 [label="arg0"];
+}
+`)
+
+	ensure.DeepEqual(t, og.Mermaid(), `flowchart TD
+  n1["*ginjects.TypeAnswerStruct<br/>named answer<br/>priority 10"]
+  n2["*ginjects.TypeForExport"]
+  n3["*ginjects.TypeAnswerStruct"]
+  n4["*ginjects.TypeForExportRepo<br/>(constructed)"]
+  n5["*ginjects.TypeAnswerStruct<br/>(private, created)"]
+  n2 -->|"Named"| n1
+  n2 -->|"Private"| n5
+  n2 -->|"Repo"| n4
+  n4 -->|"arg0"| n3
+`)
+
+	bs, err := og.JSON()
+	ensure.Nil(t, err)
+	var actual ObjectGraph
+	ensure.Nil(t, json.Unmarshal(bs, &actual))
+	ensure.DeepEqual(t, &actual, og)
+}
+
+func TestMermaidEscape(t *testing.T) {
+	ensure.DeepEqual(t, mermaidEscape(`*struct { B *T "inject:\"\"" }<br/>map[string]<int>`),
+		`*struct { B *T #quot;inject:\#quot;\#quot;#quot; }<br/>map[string]#lt;int#gt;`)
+}
+
+func TestExportGraphConcurrentReplace(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByName("flags", &TypeForRefFlagClient{})
+	ensure.Nil(t, c.Init())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			ensure.DeepEqual(t, c.ExportGraph().Nodes[0].Type, "*ginjects.TypeForRefFlagClient")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		ensure.Nil(t, c.Replace("flags", &TypeForRefFlagClient{}))
+	}
+	<-done
+}
@@ -0,0 +1,105 @@
+// Package ginjectstest 测试 ginjects 依赖注入的辅助方法
+//
+// 每个测试用例使用独立的容器，Init 之前使用 mock 替换实例，测试结束时自动 Close：
+//
+//	c := ginjectstest.New(t)
+//	publisher := ginjectstest.OverrideMock[gevents.Publisher](c, gevents.NewMockPublisher)
+//	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+//	c.MustInit()
+//	service := ginjects.MustObtainFrom[*Service](c.Container)
+package ginjectstest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/erkesi/gobean/ginjects"
+	"github.com/golang/mock/gomock"
+)
+
+// Container 测试用的容器，包含 ginjects.Container 的所有方法
+type Container struct {
+	*ginjects.Container
+	t        testing.TB
+	ctrlOnce sync.Once
+	ctrl     *gomock.Controller
+}
+
+// New 复制默认容器注入的实例以及构造函数（ginjects.Copy），测试结束时自动 Close
+func New(t testing.TB) *Container {
+	return wrap(t, ginjects.Copy())
+}
+
+// NewEmpty 创建空的容器，测试结束时自动 Close
+func NewEmpty(t testing.TB) *Container {
+	return wrap(t, ginjects.NewContainer())
+}
+
+func wrap(t testing.TB, c *ginjects.Container) *Container {
+	t.Cleanup(c.Close)
+	return &Container{Container: c, t: t}
+}
+
+// Controller 容器的 gomock.Controller，测试结束时自动校验 mock 的期望
+func (c *Container) Controller() *gomock.Controller {
+	c.ctrlOnce.Do(func() {
+		c.ctrl = gomock.NewController(c.t)
+	})
+	return c.ctrl
+}
+
+// MustInit 完成依赖注入以及实例的初始化，失败时测试失败
+func (c *Container) MustInit(opts ...ginjects.InitOption) {
+	c.t.Helper()
+	if err := c.Init(opts...); err != nil {
+		c.t.Fatalf("ginjectstest: init failed: %v", err)
+	}
+}
+
+// Override 使用 mock 替换类型为 T（Struct 指针或 interface）的实例，需要在 Init 之前调用
+func Override[T any](c *Container, mock T, opts ...ginjects.ProvideOption) {
+	c.t.Helper()
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if err := catch(func() {
+		c.OverrideByType(typ, mock, opts...)
+	}); err != nil {
+		c.t.Fatalf("ginjectstest: override %s failed: %v", typ, err)
+	}
+}
+
+// OverrideNamed 使用 mock 替换命名的实例，需要在 Init 之前调用
+func OverrideNamed(c *Container, name string, mock interface{}, opts ...ginjects.ProvideOption) {
+	c.t.Helper()
+	if err := catch(func() {
+		c.OverrideByName(name, mock, opts...)
+	}); err != nil {
+		c.t.Fatalf("ginjectstest: override object named %s failed: %v", name, err)
+	}
+}
+
+// OverrideMock 使用 gomock 生成的 mock 替换类型为 T 的实例，需要在 Init 之前调用
+// @param newMock func "gomock 生成的构造函数，如：gevents.NewMockPublisher"
+// @return M mock，用于设置期望
+func OverrideMock[T any, M any](c *Container, newMock func(ctrl *gomock.Controller) M, opts ...ginjects.ProvideOption) M {
+	c.t.Helper()
+	mock := newMock(c.Controller())
+	value, ok := interface{}(mock).(T)
+	if !ok {
+		c.t.Fatalf("ginjectstest: mock of type %T does not implement %s", mock, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	Override[T](c, value, opts...)
+	return mock
+}
+
+// catch returns the panic of fn as an error.
+func catch(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	fn()
+	return nil
+}
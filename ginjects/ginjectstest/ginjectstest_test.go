@@ -0,0 +1,75 @@
+package ginjectstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/erkesi/gobean/gevents"
+	"github.com/erkesi/gobean/ginjects"
+	"github.com/facebookgo/ensure"
+	"github.com/golang/mock/gomock"
+)
+
+type Order struct {
+	Id int
+}
+
+type OrderService struct {
+	Publisher gevents.Publisher `inject:""`
+	Repo      *OrderRepo        `inject:""`
+}
+
+func (s *OrderService) Create(ctx context.Context, order *Order) error {
+	s.Repo.orders = append(s.Repo.orders, order)
+	return s.Publisher.Publish(ctx, order)
+}
+
+type OrderRepo struct {
+	orders []*Order
+	closed bool
+}
+
+func (r *OrderRepo) Close() {
+	r.closed = true
+}
+
+func TestOverrideMock(t *testing.T) {
+	c := NewEmpty(t)
+	c.ProvideByValue(&gevents.DefaultPublisher{})
+	c.ProvideByValue(&OrderService{})
+
+	publisher := OverrideMock[gevents.Publisher](c, gevents.NewMockPublisher)
+	publisher.EXPECT().Publish(gomock.Any(), &Order{Id: 1}).Return(errors.New("unavailable"))
+	c.MustInit()
+
+	service := ginjects.MustObtainFrom[*OrderService](c.Container)
+	ensure.DeepEqual(t, service.Create(context.Background(), &Order{Id: 1}).Error(), "unavailable")
+}
+
+func TestOverride(t *testing.T) {
+	repo := &OrderRepo{}
+	t.Run("override", func(t *testing.T) {
+		c := NewEmpty(t)
+		c.ProvideByValue(&OrderRepo{})
+		c.ProvideByValue(&OrderService{})
+		c.ProvideByValue(&gevents.DefaultPublisher{})
+		Override[*OrderRepo](c, repo)
+		OverrideNamed(c, "publisher", gevents.NewMockPublisher(c.Controller()))
+		c.MustInit()
+
+		ensure.True(t, ginjects.MustObtainFrom[*OrderService](c.Container).Repo == repo)
+		_, err := ginjects.ObtainNamedFrom[gevents.Publisher](c.Container, "publisher")
+		ensure.Nil(t, err)
+	})
+	// Closed by t.Cleanup at the end of the subtest.
+	ensure.True(t, repo.closed)
+}
+
+func TestNew(t *testing.T) {
+	c := New(t)
+	c.ProvideByValue(&OrderService{})
+	OverrideMock[gevents.Publisher](c, gevents.NewMockPublisher)
+	c.MustInit()
+	ensure.NotNil(t, ginjects.MustObtainFrom[*OrderService](c.Container).Repo)
+}
@@ -13,16 +13,18 @@
 //
 // The usage pattern for the library involves struct tags. It requires the tag
 // format used by the various standard libraries, like json, xml etc. It
-// involves tags in one of the three forms below:
+// involves tags in one of the four forms below:
 //
 //     `inject:""`
 //     `inject:"private"`
 //     `inject:"dev logger"`
+//     `inject:"qualifier=mysql"`
 //
 // The first no value syntax is for the common case of a singleton dependency
 // of the associated type. The second triggers creation of a private instance
-// for the associated type. Finally the last form is asking for a named
-// dependency called "dev logger".
+// for the associated type. The third form is asking for a named dependency
+// called "dev logger". Finally the last form is asking for the dependency of
+// the associated type provided with the qualifier "mysql".
 package ginjects
 
 import (
@@ -30,9 +32,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/erkesi/gobean/glogs"
 )
@@ -42,16 +46,25 @@ type object struct {
 	index        int
 	priority     int
 	value        interface{}
-	name         string             // Optional
-	complete     bool               // If true, the value will be considered complete
-	fields       map[string]*object // Populated with the field names that were injected and their corresponding *object.
+	name         string                // Optional
+	complete     bool                  // If true, the value will be considered complete
+	fields       map[string]*object    // Populated with the field names that were injected and their corresponding *object.
+	resolutions  map[string]Resolution // Populated with the field names that were injected and how they were resolved.
 	reflectType  reflect.Type
 	reflectValue reflect.Value
-	private      bool // If true, the value will not be used and will only be populated
-	created      bool // If true, the object was created by us
-	embedded     bool // If true, the object is an embedded struct provided internally
-	initFn       func()
-	closeFn      func()
+	private      bool              // If true, the value will not be used and will only be populated
+	created      bool              // If true, the object was created by us
+	embedded     bool              // If true, the object is an embedded struct provided internally
+	args         []*object         // Populated with the constructor arguments if the object was returned by a constructor.
+	constructor  *constructor      // Populated with the constructor if the object was returned by a constructor.
+	primary      bool              // If true, the object is used when several objects are assignable to a field
+	qualifiers   []string          // Optional, matched by the qualifier tag
+	setters      map[string]string // Optional, the setters of unexported fields by field name
+	initFn       func(ctx context.Context) error
+	closeFn      func(ctx context.Context) error
+	closeTimeout time.Duration // Optional, the timeout of closeFn
+	ref          *refSlot      // Populated when a Ref field is bound to the named object
+	version      int           // Incremented by Replace, guarded by valueMu
 }
 
 // String representation suitable for human consumption.
@@ -66,11 +79,52 @@ func (o *object) String() string {
 	return builder.String()
 }
 
-func (o *object) addDep(g *graph, fieldName string, dep *object) {
+// label returns the type of the object, followed by its name if any.
+func (o *object) label() string {
+	if o.name != "" {
+		return fmt.Sprintf("%s(%s)", o.reflectType, o.name)
+	}
+	return o.reflectType.String()
+}
+
+// fieldOf returns the first field, or constructor argument, the dependency
+// was injected into.
+func (o *object) fieldOf(dep *object) string {
+	var fieldNames []string
+	for fieldName, existing := range o.fields {
+		if existing == dep {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+	if len(fieldNames) > 0 {
+		sort.Strings(fieldNames)
+		return fieldNames[0]
+	}
+	for i, arg := range o.args {
+		if arg == dep {
+			return fmt.Sprintf("arg%d", i)
+		}
+	}
+	return "?"
+}
+
+func (o *object) addDep(g *graph, fieldName string, dep *object, resolution Resolution) {
 	if o.fields == nil {
 		o.fields = make(map[string]*object)
+		o.resolutions = make(map[string]Resolution)
 	}
 	o.fields[fieldName] = dep
+	o.resolutions[fieldName] = resolution
+	o.addEdge(g, dep)
+}
+
+// addEdge records that the object depends on dep, so dep is initialized first.
+// Objects of parent graphs are initialized by their own graph, so no edge is
+// recorded for them.
+func (o *object) addEdge(g *graph, dep *object) {
+	if g.index2Object[dep.index] != dep {
+		return
+	}
 	g.edges = append(g.edges, Edge{{
 		index:    dep.index,
 		priority: dep.priority,
@@ -82,7 +136,7 @@ func (o *object) addDep(g *graph, fieldName string, dep *object) {
 
 // The graph of objects.
 type graph struct {
-	sync.Once
+	parent                    *graph // Optional, consulted when a dependency is not found in this graph
 	index                     int
 	unnamed                   []*object
 	unnamedType               map[reflect.Type]interface{}
@@ -92,6 +146,103 @@ type graph struct {
 	nodes                     []EdgeNode
 	allNodes                  []EdgeNode
 	refType2InjectFieldIndies map[reflect.Type]map[int]struct{}
+	constructors              []*constructor
+	sources                   []ConfigSource // Consulted in order by the fields tagged with value
+	dryRun                    bool           // If true, constructors are not invoked and problems are recorded instead of returned
+	problems                  []*problem     // Populated with the problems found when dryRun is true
+	lazyChecks                []*lazyCheck   // Populated with the lazy fields to resolve when dryRun is true
+	profiles                  []string       // Optional, the active profiles, nil falls back to the parent graphs
+	conditionals              []*conditional // Provided at populate if their conditions match
+	decorators                []*decorator   // Applied to the objects injected into the dependents of their type
+	decorated                 map[decoratedKey]decoratedValue
+	decoratedMu               sync.Mutex
+	privates                  []*Container // The containers of the private Lazy and Provider instances
+	privatesMu                sync.Mutex
+}
+
+func newGraph(parent *graph) *graph {
+	return &graph{
+		parent:                    parent,
+		refType2InjectFieldIndies: map[reflect.Type]map[int]struct{}{},
+	}
+}
+
+// findNamed returns the object with the given name, falling back to the parent
+// graphs if it was not provided to this graph.
+func (g *graph) findNamed(name string) *object {
+	for cur := g; cur != nil; cur = cur.parent {
+		if o, ok := cur.named[name]; ok {
+			return o
+		}
+	}
+	return nil
+}
+
+// findAssignable returns the non-private unnamed objects assignable to typ
+// from the nearest graph having any, so a child graph overrides its parents.
+func (g *graph) findAssignable(typ reflect.Type) []*object {
+	for cur := g; cur != nil; cur = cur.parent {
+		if found := cur.assignable(typ); len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
+
+// assignable returns the non-private unnamed objects of this graph assignable
+// to typ.
+func (g *graph) assignable(typ reflect.Type) []*object {
+	var found []*object
+	for _, existing := range g.unnamed {
+		if existing.private {
+			continue
+		}
+		if existing.reflectType.AssignableTo(typ) {
+			found = append(found, existing)
+		}
+	}
+	return found
+}
+
+// findCandidates returns the objects assignable to typ. Given a qualifier only
+// the objects having it are returned, and when several objects are found the
+// primary ones are preferred.
+func (g *graph) findCandidates(typ reflect.Type, qualifier string) []*object {
+	var found []*object
+	if qualifier == "" {
+		found = g.findAssignable(typ)
+	} else {
+		for cur := g; cur != nil && len(found) == 0; cur = cur.parent {
+			found = qualified(cur.assignable(typ), qualifier)
+		}
+	}
+	if len(found) <= 1 {
+		return found
+	}
+	var primaries []*object
+	for _, o := range found {
+		if o.primary {
+			primaries = append(primaries, o)
+		}
+	}
+	if len(primaries) > 0 {
+		return primaries
+	}
+	return found
+}
+
+// qualified returns the objects having the qualifier.
+func qualified(objects []*object, qualifier string) []*object {
+	var found []*object
+	for _, o := range objects {
+		for _, q := range o.qualifiers {
+			if q == qualifier {
+				found = append(found, o)
+				break
+			}
+		}
+	}
+	return found
 }
 
 // provide objects to the graph. The object documentation describes
@@ -108,12 +259,7 @@ func (g *graph) provide(objects ...*object) error {
 			g.index2Object = make(map[int]*object)
 		}
 		g.index2Object[o.index] = o
-		if lc, ok := o.value.(ObjectInit); ok {
-			o.initFn = lc.Init
-		}
-		if lc, ok := o.value.(ObjectClose); ok {
-			o.closeFn = lc.Close
-		}
+		o.initFn, o.closeFn = lifecycleFns(o.value)
 		o.reflectType = reflect.TypeOf(o.value)
 		o.reflectValue = reflect.ValueOf(o.value)
 
@@ -174,6 +320,18 @@ func (g *graph) provide(objects ...*object) error {
 
 // populate the incomplete objects.
 func (g *graph) populate() error {
+	// The conditions are evaluated before anything is populated, they only
+	// depend on what was provided.
+	if err := g.resolveConditionals(); err != nil {
+		return err
+	}
+
+	// Constructors are invoked first so their results can be injected into
+	// the fields of the other objects.
+	if err := g.invokeConstructors(); err != nil {
+		return err
+	}
+
 	for _, o := range g.named {
 		if o.complete {
 			continue
@@ -204,6 +362,12 @@ func (g *graph) populate() error {
 		}
 	}
 
+	// Config values are set before the Interface values are injected, all
+	// missing or invalid values are reported together.
+	if err := g.populateAllValues(); err != nil {
+		return err
+	}
+
 	// A Second pass handles injecting Interface values to ensure we have created
 	// all concrete types first.
 	for _, o := range g.unnamed {
@@ -225,220 +389,299 @@ func (g *graph) populate() error {
 			return err
 		}
 	}
-	nodes, circles, err := Toposort(g.edges, g.allNodes)
+	nodes, _, err := Toposort(g.edges, g.allNodes)
 	if err != nil {
-		nodes := make([]string, 0, len(circles))
-		for _, node := range circles {
-			nodes = append(nodes, g.index2Object[node.index].String())
+		var cycleErr *CycleError
+		if errors.As(err, &cycleErr) {
+			cycleErr.Cycles = g.namedCycles(cycleErr.nodes)
 		}
-		if len(nodes) > 0 {
-			return fmt.Errorf("ginjects: object depend inject graph is circle, %s", strings.Join(nodes, " >> "))
-		}
-		return err
+		return g.report(nil, "", err)
 	}
 	g.nodes = nodes
 	return nil
 }
 
+// namedCycles names every object of the cycles by its type and the field
+// injecting the next object of the cycle, e.g. *pkg.A.B.
+func (g *graph) namedCycles(cycles [][]EdgeNode) [][]string {
+	named := make([][]string, 0, len(cycles))
+	for _, cycle := range cycles {
+		chain := make([]string, 0, len(cycle))
+		for i, node := range cycle {
+			o := g.index2Object[node.index]
+			next := g.index2Object[cycle[(i+1)%len(cycle)].index]
+			chain = append(chain, o.label()+"."+o.fieldOf(next))
+		}
+		named = append(named, chain)
+	}
+	return named
+}
+
 func (g *graph) populateExplicit(o *object) error {
 	// Ignore named value types.
 	if o.name != "" && !isStructPtr(o.reflectType) {
 		return nil
 	}
 
-StructLoop:
 	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
-		field := o.reflectValue.Elem().Field(i)
-		fieldType := field.Type()
-		fieldTag := o.reflectType.Elem().Field(i).Tag
-		fieldName := o.reflectType.Elem().Field(i).Name
-		tag, err := parseTag(string(fieldTag))
+		if err := g.populateExplicitField(o, i); err != nil {
+			if err := g.report(o, o.reflectType.Elem().Field(i).Name, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *graph) populateExplicitField(o *object, i int) (err error) {
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := field.Type()
+	fieldTag := o.reflectType.Elem().Field(i).Tag
+	fieldName := o.reflectType.Elem().Field(i).Name
+	tag, err := parseTag(string(fieldTag))
+	if err != nil {
+		return fmt.Errorf(
+			"ginjects: unexpected tag format `%s` for field %s in type %s",
+			string(fieldTag),
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
+
+	// Skip fields without a tag.
+	if tag == nil {
+		return nil
+	}
+	g.addInjectFieldIndex(o.reflectType, i)
+	// Cannot be used with unexported fields, unless they have a setter.
+	field, apply, err := g.settableField(o, i, tag)
+	if err != nil {
+		return err
+	}
+	if apply != nil {
+		defer func() {
+			if err == nil {
+				err = apply()
+			}
+		}()
+	}
+
+	// Inline tag on anything besides a struct is considered invalid.
+	if tag.Inline && fieldType.Kind() != reflect.Struct {
+		return fmt.Errorf(
+			"ginjects: inline requested on non inlined field %s in type %s",
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
+
+	// Don't overwrite existing values.
+	if !isNilOrZero(field, fieldType) {
+		return nil
+	}
+
+	// Lazy fields are bound to a resolver, the dependency is obtained on the
+	// first call so no edge is added.
+	if isLazyField(fieldType) {
+		lf := field.Addr().Interface().(lazyField)
+		resolve, err := g.lazyResolver(o, fieldName, lf.elemType(), tag)
 		if err != nil {
+			return err
+		}
+		lf.bind(resolve)
+		if g.dryRun && !tag.Private {
+			g.lazyChecks = append(g.lazyChecks, &lazyCheck{object: o, field: fieldName, resolve: resolve})
+		}
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(),
+				"ginjects: bound lazy field %s in %s",
+				fieldName,
+				o,
+			)
+		}
+		return nil
+	}
+
+	// Ref fields are bound to the named object, they see its replaced values.
+	if isRefField(fieldType) {
+		return g.bindRefField(o, fieldName, field, tag)
+	}
+
+	// Named injects must have been explicitly provided.
+	if tag.Name != "" {
+		existing := g.findNamed(tag.Name)
+		if existing == nil {
 			return fmt.Errorf(
-				"ginjects: unexpected tag format `%s` for field %s in type %s",
-				string(fieldTag),
+				"ginjects: did not find object named %s required by field %s in type %s",
+				tag.Name,
 				o.reflectType.Elem().Field(i).Name,
 				o.reflectType,
 			)
 		}
 
-		// Skip fields without a tag.
-		if tag == nil {
-			continue
+		if !existing.reflectType.AssignableTo(fieldType) {
+			return fmt.Errorf(
+				"ginjects: object named %s of type %s is not assignable to field %s (%s) in type %s",
+				tag.Name,
+				fieldType,
+				o.reflectType.Elem().Field(i).Name,
+				existing.reflectType,
+				o.reflectType,
+			)
 		}
-		g.addInjectFieldIndex(o.reflectType, i)
-		// Cannot be used with unexported fields.
-		if !field.CanSet() {
+
+		value, err := g.decorate(existing, fieldType)
+		if err != nil {
+			return err
+		}
+		field.Set(value)
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(),
+				"ginjects: assigned %s to field %s in %s",
+				existing,
+				o.reflectType.Elem().Field(i).Name,
+				o,
+			)
+		}
+		o.addDep(g, fieldName, existing, ResolvedByName)
+		return nil
+	}
+
+	// Inline struct values indicate we want to traverse into it, but not
+	// inject itself. We require an explicit "inline" tag for this to work.
+	if fieldType.Kind() == reflect.Struct {
+		if tag.Private {
 			return fmt.Errorf(
-				"ginjects: inject requested on unexported field %s in type %s",
+				"ginjects: cannot use private inject on inline struct on field %s in type %s",
 				o.reflectType.Elem().Field(i).Name,
 				o.reflectType,
 			)
 		}
 
-		// Inline tag on anything besides a struct is considered invalid.
-		if tag.Inline && fieldType.Kind() != reflect.Struct {
+		if !tag.Inline {
 			return fmt.Errorf(
-				"ginjects: inline requested on non inlined field %s in type %s",
+				"ginjects: inline struct on field %s in type %s requires an explicit \"inline\" tag",
 				o.reflectType.Elem().Field(i).Name,
 				o.reflectType,
 			)
 		}
 
-		// Don't overwrite existing values.
-		if !isNilOrZero(field, fieldType) {
-			continue
+		err := g.provide(&object{
+			value:    field.Addr().Interface(),
+			private:  true,
+			embedded: o.reflectType.Elem().Field(i).Anonymous,
+		})
+		if err != nil {
+			return err
 		}
+		return nil
+	}
 
-		// Named injects must have been explicitly provided.
-		if tag.Name != "" {
-			existing := g.named[tag.Name]
-			if existing == nil {
-				return fmt.Errorf(
-					"ginjects: did not find object named %s required by field %s in type %s",
-					tag.Name,
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
-			}
+	// Interface injection is handled in a second pass.
+	if fieldType.Kind() == reflect.Interface {
+		return nil
+	}
 
-			if !existing.reflectType.AssignableTo(fieldType) {
-				return fmt.Errorf(
-					"ginjects: object named %s of type %s is not assignable to field %s (%s) in type %s",
-					tag.Name,
-					fieldType,
-					o.reflectType.Elem().Field(i).Name,
-					existing.reflectType,
-					o.reflectType,
-				)
-			}
+	// Collections of objects are handled in a second pass.
+	if !tag.Private && isCollection(fieldType) {
+		return nil
+	}
 
-			field.Set(reflect.ValueOf(existing.value))
-			if glogs.Log != nil {
-				glogs.Log.Debugf(context.TODO(),
-					"ginjects: assigned %s to field %s in %s",
-					existing,
-					o.reflectType.Elem().Field(i).Name,
-					o,
-				)
-			}
-			o.addDep(g, fieldName, existing)
-			continue StructLoop
+	// Maps are created and required to be private.
+	if fieldType.Kind() == reflect.Map {
+		if !tag.Private {
+			return fmt.Errorf(
+				"ginjects: inject on map field %s in type %s must be named or private",
+				o.reflectType.Elem().Field(i).Name,
+				o.reflectType,
+			)
 		}
 
-		// Inline struct values indicate we want to traverse into it, but not
-		// inject itself. We require an explicit "inline" tag for this to work.
-		if fieldType.Kind() == reflect.Struct {
-			if tag.Private {
-				return fmt.Errorf(
-					"ginjects: cannot use private inject on inline struct on field %s in type %s",
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
-			}
+		field.Set(reflect.MakeMap(fieldType))
+		if glogs.Log != nil {
+			glogs.Log.Debugf(context.TODO(),
+				"ginjects: made map for field %s in %s",
+				o.reflectType.Elem().Field(i).Name,
+				o,
+			)
+		}
+		return nil
+	}
 
-			if !tag.Inline {
-				return fmt.Errorf(
-					"ginjects: inline struct on field %s in type %s requires an explicit \"inline\" tag",
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
-			}
+	// Can only inject Pointers from here on.
+	if !isStructPtr(fieldType) {
+		return fmt.Errorf(
+			"ginjects: found inject tag on unsupported field %s in type %s",
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
 
-			err := g.provide(&object{
-				value:    field.Addr().Interface(),
-				private:  true,
-				embedded: o.reflectType.Elem().Field(i).Anonymous,
-			})
+	// Unless it's a private inject, we'll look for an existing instance of the
+	// same type.
+	if !tag.Private {
+		if found := g.findCandidates(fieldType, tag.Qualifier); len(found) > 0 {
+			existing := found[0]
+			value, err := g.decorate(existing, fieldType)
 			if err != nil {
 				return err
 			}
-			continue
-		}
-
-		// Interface injection is handled in a second pass.
-		if fieldType.Kind() == reflect.Interface {
-			continue
-		}
-
-		// Maps are created and required to be private.
-		if fieldType.Kind() == reflect.Map {
-			if !tag.Private {
-				return fmt.Errorf(
-					"ginjects: inject on map field %s in type %s must be named or private",
-					o.reflectType.Elem().Field(i).Name,
-					o.reflectType,
-				)
-			}
-
-			field.Set(reflect.MakeMap(fieldType))
+			field.Set(value)
 			if glogs.Log != nil {
 				glogs.Log.Debugf(context.TODO(),
-					"ginjects: made map for field %s in %s",
+					"ginjects: assigned existing %s to field %s in %s",
+					existing,
 					o.reflectType.Elem().Field(i).Name,
 					o,
 				)
 			}
-			continue
-		}
-
-		// Can only inject Pointers from here on.
-		if !isStructPtr(fieldType) {
-			return fmt.Errorf(
-				"ginjects: found inject tag on unsupported field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+			o.addDep(g, fieldName, existing, ResolvedByType)
+			return nil
 		}
+	}
 
-		// Unless it's a private inject, we'll look for an existing instance of the
-		// same type.
-		if !tag.Private {
-			for _, existing := range g.unnamed {
-				if existing.private {
-					continue
-				}
-				if existing.reflectType.AssignableTo(fieldType) {
-					field.Set(reflect.ValueOf(existing.value))
-					if glogs.Log != nil {
-						glogs.Log.Debugf(context.TODO(),
-							"ginjects: assigned existing %s to field %s in %s",
-							existing,
-							o.reflectType.Elem().Field(i).Name,
-							o,
-						)
-					}
-					o.addDep(g, fieldName, existing)
-					continue StructLoop
-				}
-			}
-		}
+	// Qualified injects must have been explicitly provided.
+	if tag.Qualifier != "" {
+		return fmt.Errorf(
+			"ginjects: did not find object qualified %s required by field %s in type %s",
+			tag.Qualifier,
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
 
-		newValue := reflect.New(fieldType.Elem())
-		newObject := &object{
-			value:   newValue.Interface(),
-			private: tag.Private,
-			created: true,
-		}
+	newValue := reflect.New(fieldType.Elem())
+	newObject := &object{
+		value:   newValue.Interface(),
+		private: tag.Private,
+		created: true,
+	}
 
-		// Add the newly ceated object to the known set of objects.
-		err = g.provide(newObject)
-		if err != nil {
-			return err
-		}
+	// Add the newly ceated object to the known set of objects.
+	err = g.provide(newObject)
+	if err != nil {
+		return err
+	}
 
-		// Finally assign the newly created object to our field.
-		field.Set(newValue)
-		if glogs.Log != nil {
-			glogs.Log.Debugf(context.TODO(),
-				"ginjects: assigned newly created %s to field %s in %s",
-				newObject,
-				o.reflectType.Elem().Field(i).Name,
-				o,
-			)
-		}
-		o.addDep(g, fieldName, newObject)
+	// Finally assign the newly created object to our field.
+	value, err := g.decorate(newObject, fieldType)
+	if err != nil {
+		return err
+	}
+	field.Set(value)
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(),
+			"ginjects: assigned newly created %s to field %s in %s",
+			newObject,
+			o.reflectType.Elem().Field(i).Name,
+			o,
+		)
 	}
+	resolution := ResolvedCreated
+	if tag.Private {
+		resolution = ResolvedPrivate
+	}
+	o.addDep(g, fieldName, newObject, resolution)
 	return nil
 }
 
@@ -449,94 +692,227 @@ func (g *graph) populateUnnamedInterface(o *object) error {
 	}
 
 	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
-		field := o.reflectValue.Elem().Field(i)
-		fieldType := field.Type()
-		fieldTag := o.reflectType.Elem().Field(i).Tag
-		fieldName := o.reflectType.Elem().Field(i).Name
-		tag, err := parseTag(string(fieldTag))
-		if err != nil {
-			return fmt.Errorf(
-				"ginjects: unexpected tag format `%s` for field %s in type %s",
-				string(fieldTag),
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
-		}
-
-		// Skip fields without a tag.
-		if tag == nil {
-			continue
-		}
-		g.addInjectFieldIndex(o.reflectType, i)
-		// We only handle interface injection here. Other cases including errors
-		// are handled in the first pass when we inject pointers.
-		if fieldType.Kind() != reflect.Interface {
-			continue
+		if err := g.populateInterfaceField(o, i); err != nil {
+			if err := g.report(o, o.reflectType.Elem().Field(i).Name, err); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		// Interface injection can't be private because we can't instantiate new
-		// instances of an interface.
-		if tag.Private {
-			return fmt.Errorf(
-				"ginjects: found private inject tag on interface field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
-		}
+func (g *graph) populateInterfaceField(o *object, i int) (err error) {
+	field := o.reflectValue.Elem().Field(i)
+	fieldType := field.Type()
+	fieldTag := o.reflectType.Elem().Field(i).Tag
+	fieldName := o.reflectType.Elem().Field(i).Name
+	tag, err := parseTag(string(fieldTag))
+	if err != nil {
+		return fmt.Errorf(
+			"ginjects: unexpected tag format `%s` for field %s in type %s",
+			string(fieldTag),
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
 
-		// Don't overwrite existing values.
+	// Skip fields without a tag.
+	if tag == nil {
+		return nil
+	}
+	g.addInjectFieldIndex(o.reflectType, i)
+	// Unexported fields without a setter are reported in the first pass.
+	field, apply, err := g.settableField(o, i, tag)
+	if err != nil {
+		return err
+	}
+	if apply != nil {
+		defer func() {
+			if err == nil {
+				err = apply()
+			}
+		}()
+	}
+	// Slices and maps of interfaces or pointers to structs are filled with
+	// every assignable object.
+	if !tag.Private && tag.Name == "" && isCollection(fieldType) {
 		if !isNilOrZero(field, fieldType) {
-			continue
+			return nil
 		}
+		return g.populateCollection(o, fieldName, field, tag.Qualifier)
+	}
+
+	// We only handle interface injection here. Other cases including errors
+	// are handled in the first pass when we inject pointers.
+	if fieldType.Kind() != reflect.Interface {
+		return nil
+	}
 
-		// Named injects must have already been handled in populateExplicit.
-		if tag.Name != "" {
-			panic(fmt.Sprintf("ginjects: unhandled named instance with name %s", tag.Name))
+	// Interface injection can't be private because we can't instantiate new
+	// instances of an interface.
+	if tag.Private {
+		return fmt.Errorf(
+			"ginjects: found private inject tag on interface field %s in type %s",
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
+
+	// Don't overwrite existing values.
+	if !isNilOrZero(field, fieldType) {
+		return nil
+	}
+
+	// Named injects must have already been handled in populateExplicit, unless
+	// the named object is missing when validating.
+	if tag.Name != "" {
+		if g.dryRun {
+			return nil
 		}
+		panic(fmt.Sprintf("ginjects: unhandled named instance with name %s", tag.Name))
+	}
+
+	// Find one, and only one assignable value for the field, the primary or
+	// qualified one if there are several.
+	found := g.findCandidates(fieldType, tag.Qualifier)
+	if len(found) > 1 {
+		return fmt.Errorf(
+			"ginjects: found two assignable values for field %s in type %s. one type "+
+				"%s with value %v and another type %s with value %v",
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+			found[0].reflectType,
+			found[0].value,
+			found[1].reflectType,
+			found[1].reflectValue,
+		)
+	}
 
-		// Find one, and only one assignable value for the field.
-		var found *object
-		for _, existing := range g.unnamed {
-			if existing.private {
+	// If we didn't find an assignable value, we're missing something.
+	if len(found) == 0 && tag.Qualifier != "" {
+		return fmt.Errorf(
+			"ginjects: found no assignable value qualified %s for field %s in type %s",
+			tag.Qualifier,
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
+	if len(found) == 0 {
+		return fmt.Errorf(
+			"ginjects: found no assignable value for field %s in type %s",
+			o.reflectType.Elem().Field(i).Name,
+			o.reflectType,
+		)
+	}
+
+	existing := found[0]
+	value, err := g.decorate(existing, fieldType)
+	if err != nil {
+		return err
+	}
+	field.Set(value)
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(),
+			"ginjects: assigned existing %s to interface field %s in %s",
+			existing,
+			o.reflectType.Elem().Field(i).Name,
+			o,
+		)
+	}
+	o.addDep(g, fieldName, existing, ResolvedByInterface)
+	return nil
+}
+
+// populateCollection fills the slice field with every assignable object, or
+// the map field with every assignable named object keyed by name.
+func (g *graph) populateCollection(o *object, fieldName string, field reflect.Value, qualifier string) error {
+	fieldType := field.Type()
+	found := g.findCollection(fieldType.Elem(), o)
+	if qualifier != "" {
+		found = qualified(found, qualifier)
+	}
+	switch fieldType.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(fieldType, 0, len(found))
+		for _, existing := range found {
+			value, err := g.decorate(existing, fieldType.Elem())
+			if err != nil {
+				return err
+			}
+			o.addDep(g, fmt.Sprintf("%s[%d]", fieldName, slice.Len()), existing, ResolvedByCollection)
+			slice = reflect.Append(slice, value)
+		}
+		field.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMapWithSize(fieldType, len(found))
+		for _, existing := range found {
+			if existing.name == "" {
 				continue
 			}
-			if existing.reflectType.AssignableTo(fieldType) {
-				if found != nil {
-					return fmt.Errorf(
-						"ginjects: found two assignable values for field %s in type %s. one type "+
-							"%s with value %v and another type %s with value %v",
-						o.reflectType.Elem().Field(i).Name,
-						o.reflectType,
-						found.reflectType,
-						found.value,
-						existing.reflectType,
-						existing.reflectValue,
-					)
-				}
-				found = existing
-				field.Set(reflect.ValueOf(existing.value))
-				if glogs.Log != nil {
-					glogs.Log.Debugf(context.TODO(),
-						"ginjects: assigned existing %s to interface field %s in %s",
-						existing,
-						o.reflectType.Elem().Field(i).Name,
-						o,
-					)
-				}
-				o.addDep(g, fieldName, existing)
+			value, err := g.decorate(existing, fieldType.Elem())
+			if err != nil {
+				return err
 			}
+			o.addDep(g, fmt.Sprintf("%s[%s]", fieldName, existing.name), existing, ResolvedByCollection)
+			m.SetMapIndex(reflect.ValueOf(existing.name).Convert(fieldType.Key()), value)
 		}
+		field.Set(m)
+	}
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(),
+			"ginjects: assigned %d objects to collection field %s in %s",
+			field.Len(),
+			fieldName,
+			o,
+		)
+	}
+	return nil
+}
 
-		// If we didn't find an assignable value, we're missing something.
-		if found == nil {
-			return fmt.Errorf(
-				"ginjects: found no assignable value for field %s in type %s",
-				o.reflectType.Elem().Field(i).Name,
-				o.reflectType,
-			)
+// findCollection returns every non-private object assignable to typ, named as
+// well as unnamed, ordered by priority. Objects of the parent graphs are
+// included unless overridden by an object of the same type or name.
+func (g *graph) findCollection(typ reflect.Type, exclude *object) []*object {
+	var found []*object
+	seenTypes := make(map[reflect.Type]bool)
+	seenNames := make(map[string]bool)
+	for cur := g; cur != nil; cur = cur.parent {
+		var objects []*object
+		for _, existing := range cur.unnamed {
+			if existing.private || existing == exclude || seenTypes[existing.reflectType] {
+				continue
+			}
+			if existing.reflectType.AssignableTo(typ) {
+				objects = append(objects, existing)
+			}
+		}
+		for name, existing := range cur.named {
+			if existing == exclude || seenNames[name] {
+				continue
+			}
+			if existing.reflectType.AssignableTo(typ) {
+				objects = append(objects, existing)
+			}
+		}
+		for _, existing := range objects {
+			if existing.name == "" {
+				seenTypes[existing.reflectType] = true
+			} else {
+				seenNames[existing.name] = true
+			}
 		}
+		sort.Slice(objects, func(i, j int) bool {
+			if objects[i].priority == objects[j].priority {
+				return objects[i].index < objects[j].index
+			}
+			return objects[i].priority > objects[j].priority
+		})
+		found = append(found, objects...)
 	}
-	return nil
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].priority > found[j].priority
+	})
+	return found
 }
 
 // objects returns all known objects, named as well as unnamed. The returned
@@ -559,9 +935,10 @@ var (
 )
 
 type tag struct {
-	Name    string
-	Inline  bool
-	Private bool
+	Name      string
+	Inline    bool
+	Private   bool
+	Qualifier string
 }
 
 func parseTag(t string) (*tag, error) {
@@ -581,6 +958,9 @@ func parseTag(t string) (*tag, error) {
 	if value == "private" {
 		return injectPrivate, nil
 	}
+	if strings.HasPrefix(value, "qualifier=") {
+		return &tag{Qualifier: strings.TrimPrefix(value, "qualifier=")}, nil
+	}
 	return &tag{Name: value}, nil
 }
 
@@ -588,10 +968,25 @@ func isStructPtr(t reflect.Type) bool {
 	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
 }
 
+// isCollection reports whether t is a slice, or a map keyed by string, of
+// interfaces or pointers to structs.
+func isCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return false
+		}
+	default:
+		return false
+	}
+	return isStructPtr(t.Elem()) || t.Elem().Kind() == reflect.Interface
+}
+
 func isNilOrZero(v reflect.Value, t reflect.Type) bool {
 	switch v.Kind() {
 	default:
-		return reflect.DeepEqual(v.Interface(), reflect.Zero(t).Interface())
+		return v.IsZero()
 	case reflect.Interface, reflect.Ptr:
 		return v.IsNil()
 	}
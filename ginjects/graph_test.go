@@ -1120,3 +1120,154 @@ func TestStructTagExtract(t *testing.T) {
 		}
 	}
 }
+
+type TypeForCollectionHandler interface {
+	Handle() string
+}
+
+type TypeForCollectionHandlerA struct{}
+
+func (h *TypeForCollectionHandlerA) Handle() string { return "a" }
+
+type TypeForCollectionHandlerB struct{}
+
+func (h *TypeForCollectionHandlerB) Handle() string { return "b" }
+
+type TypeForCollection struct {
+	Handlers      []TypeForCollectionHandler          `inject:""`
+	NamedHandlers map[string]TypeForCollectionHandler `inject:""`
+	As            []*TypeForCollectionHandlerA        `inject:""`
+}
+
+func (c *TypeForCollection) Handle() string { return "collection" }
+
+func TestInjectCollection(t *testing.T) {
+	g := newGraph(nil)
+	var v TypeForCollection
+	a := &TypeForCollectionHandlerA{}
+	b := &TypeForCollectionHandlerB{}
+	namedA := &TypeForCollectionHandlerA{}
+	ensure.Nil(t, g.provide(
+		&object{value: &v},
+		&object{value: a, priority: 1},
+		&object{value: b, priority: 2},
+		&object{value: namedA, name: "named_a"},
+		&object{value: &TypeAnswerStruct{}, name: "answer"},
+	))
+	ensure.Nil(t, g.populate())
+
+	handlers := make([]string, 0, len(v.Handlers))
+	for _, h := range v.Handlers {
+		handlers = append(handlers, h.Handle())
+	}
+	// ordered by priority, the object itself is excluded.
+	ensure.DeepEqual(t, handlers, []string{"b", "a", "a"})
+	ensure.True(t, v.Handlers[2] == namedA)
+	ensure.DeepEqual(t, v.NamedHandlers, map[string]TypeForCollectionHandler{"named_a": namedA})
+	ensure.DeepEqual(t, v.As, []*TypeForCollectionHandlerA{a, namedA})
+
+	// the handlers are initialized first.
+	var actual []string
+	for _, o := range g.objects() {
+		if o.name != "answer" {
+			actual = append(actual, o.String())
+		}
+	}
+	ensure.DeepEqual(t, actual[len(actual)-1], "\"*ginjects.TypeForCollection\"")
+}
+
+func TestInjectEmptyCollection(t *testing.T) {
+	var v struct {
+		Handlers []TypeForCollectionHandler `inject:""`
+	}
+	ensure.Nil(t, populate(&v))
+	ensure.NotNil(t, v.Handlers)
+	ensure.DeepEqual(t, len(v.Handlers), 0)
+}
+
+func TestInjectCollectionOverride(t *testing.T) {
+	parent := newGraph(nil)
+	ensure.Nil(t, parent.provide(
+		&object{value: &TypeForCollectionHandlerA{}},
+		&object{value: &TypeForCollectionHandlerB{}, name: "b"},
+	))
+	ensure.Nil(t, parent.populate())
+
+	g := newGraph(parent)
+	a := &TypeForCollectionHandlerA{}
+	b := &TypeForCollectionHandlerB{}
+	var v struct {
+		Handlers      []TypeForCollectionHandler          `inject:""`
+		NamedHandlers map[string]TypeForCollectionHandler `inject:""`
+	}
+	ensure.Nil(t, g.provide(&object{value: &v}, &object{value: a}, &object{value: b, name: "b"}))
+	ensure.Nil(t, g.populate())
+	ensure.DeepEqual(t, v.Handlers, []TypeForCollectionHandler{a, b})
+	ensure.DeepEqual(t, v.NamedHandlers, map[string]TypeForCollectionHandler{"b": b})
+}
+
+type TypeForQualifier struct {
+	Handler   TypeForCollectionHandler   `inject:""`
+	Qualified TypeForCollectionHandler   `inject:"qualifier=b"`
+	Handlers  []TypeForCollectionHandler `inject:"qualifier=b"`
+}
+
+func TestInjectPrimary(t *testing.T) {
+	g := newGraph(nil)
+	var v TypeForQualifier
+	a := &TypeForCollectionHandlerA{}
+	b := &TypeForCollectionHandlerB{}
+	ensure.Nil(t, g.provide(
+		&object{value: &v},
+		&object{value: a, primary: true},
+		&object{value: b, qualifiers: []string{"b"}},
+	))
+	ensure.Nil(t, g.populate())
+	ensure.True(t, v.Handler == a)
+	ensure.True(t, v.Qualified == b)
+	ensure.DeepEqual(t, v.Handlers, []TypeForCollectionHandler{b})
+}
+
+func TestInjectTwoPrimary(t *testing.T) {
+	g := newGraph(nil)
+	var v struct {
+		Handler TypeForCollectionHandler `inject:""`
+	}
+	ensure.Nil(t, g.provide(
+		&object{value: &v},
+		&object{value: &TypeForCollectionHandlerA{}, primary: true},
+		&object{value: &TypeForCollectionHandlerB{}, primary: true},
+	))
+	err := g.populate()
+	ensure.NotNil(t, err)
+	if !strings.HasPrefix(err.Error(), "ginjects: found two assignable values for field Handler") {
+		t.Fatalf("unexpected error %s", err)
+	}
+}
+
+func TestInjectQualifierMissing(t *testing.T) {
+	g := newGraph(nil)
+	var v struct {
+		Handler TypeForCollectionHandler `inject:"qualifier=mysql"`
+		A       *TypeAnswerStruct        `inject:"qualifier=mysql"`
+	}
+	ensure.Nil(t, g.provide(
+		&object{value: &v},
+		&object{value: &TypeForCollectionHandlerA{}, qualifiers: []string{"redis"}},
+	))
+	err := g.populate()
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: did not find object qualified mysql required by field A in type *struct { Handler ginjects.TypeForCollectionHandler \"inject:\\\"qualifier=mysql\\\"\"; A *ginjects.TypeAnswerStruct \"inject:\\\"qualifier=mysql\\\"\" }")
+
+	g = newGraph(nil)
+	var w struct {
+		Handler TypeForCollectionHandler `inject:"qualifier=mysql"`
+	}
+	ensure.Nil(t, g.provide(
+		&object{value: &w},
+		&object{value: &TypeForCollectionHandlerA{}, qualifiers: []string{"redis"}},
+	))
+	err = g.populate()
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: found no assignable value qualified mysql for field Handler in type *struct { Handler ginjects.TypeForCollectionHandler \"inject:\\\"qualifier=mysql\\\"\" }")
+}
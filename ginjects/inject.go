@@ -2,11 +2,8 @@ package ginjects
 
 import (
 	"context"
-	"fmt"
 	"math"
-	"reflect"
-
-	"github.com/erkesi/gobean/glogs"
+	"time"
 )
 
 type ObjectInit interface {
@@ -17,35 +14,64 @@ type ObjectClose interface {
 	Close()
 }
 
-var g = &graph{refType2InjectFieldIndies: map[reflect.Type]map[int]struct{}{}}
+// ObjectInitContext 实例初始化，返回错误时 Init 失败，并按照逆向顺序销毁已经初始化的实例
+// 同时实现 ObjectInit 时，只调用 InitContext
+type ObjectInitContext interface {
+	InitContext(ctx context.Context) error
+}
+
+// ObjectCloseContext 实例销毁
+// 同时实现 ObjectClose 时，只调用 CloseContext
+type ObjectCloseContext interface {
+	CloseContext(ctx context.Context) error
+}
 
-func Init() {
-	g.Do(func() {
-		if err := g.populate(); err != nil {
-			panic(err)
+// lifecycleFns returns the init and close functions of the value, preferring
+// the context aware interfaces.
+func lifecycleFns(value interface{}) (initFn, closeFn func(ctx context.Context) error) {
+	if lc, ok := value.(ObjectInitContext); ok {
+		initFn = lc.InitContext
+	} else if lc, ok := value.(ObjectInit); ok {
+		initFn = func(ctx context.Context) error {
+			lc.Init()
+			return nil
 		}
-		for _, obj := range g.objects() {
-			initFn := obj.initFn
-			if initFn != nil {
-				if glogs.Log != nil {
-					glogs.Log.Debugf(context.TODO(), "ginjects: init inject object(%v)", obj)
-				}
-				initFn()
-			}
+	}
+	if lc, ok := value.(ObjectCloseContext); ok {
+		closeFn = lc.CloseContext
+	} else if lc, ok := value.(ObjectClose); ok {
+		closeFn = func(ctx context.Context) error {
+			lc.Close()
+			return nil
 		}
-	})
+	}
+	return initFn, closeFn
 }
 
+// defaultContainer 包级别方法使用的默认容器
+var defaultContainer = NewContainer()
+
+// Init 完成依赖注入（包括调用构造函数）以及按照顺序完成实例的初始化
+// @return error 依赖注入、构造函数或实例初始化返回的错误
+func Init(opts ...InitOption) error {
+	return defaultContainer.Init(opts...)
+}
+
+// InitContext 同 Init，ctx 传递给实例的 InitContext 方法
+// @return error 依赖注入的错误或实例初始化的错误（*ObjectError），初始化失败时已经初始化的实例会被销毁
+func InitContext(ctx context.Context, opts ...InitOption) error {
+	return defaultContainer.InitContext(ctx, opts...)
+}
+
+// Close 按照实例初始化的逆向顺序销毁实例
 func Close() {
-	for i := len(g.objects()) - 1; i >= 0; i-- {
-		closeFn := g.objects()[i].closeFn
-		if closeFn != nil {
-			if glogs.Log != nil {
-				glogs.Log.Debugf(context.TODO(), "ginjects: close inject object(%v)", g.objects()[i])
-			}
-			closeFn()
-		}
-	}
+	defaultContainer.Close()
+}
+
+// CloseContext 同 Close，ctx 结束后不再等待实例的销毁
+// @return error 全部销毁失败的实例的错误（Errors，元素为 *ObjectError）
+func CloseContext(ctx context.Context) error {
+	return defaultContainer.CloseContext(ctx)
 }
 
 type ProvideOption func(opt *provideOptions)
@@ -65,8 +91,46 @@ func WithProvidePriorityTop1() ProvideOption {
 	}
 }
 
+// WithProvidePrimary 注入多个匹配 interface 的实例时，优先使用该实例
+func WithProvidePrimary() ProvideOption {
+	return func(opt *provideOptions) {
+		opt.primary = true
+	}
+}
+
+// WithProvideQualifier 实例的限定符，匹配标签 `inject:"qualifier=mysql"` 的字段
+func WithProvideQualifier(qualifiers ...string) ProvideOption {
+	return func(opt *provideOptions) {
+		opt.qualifiers = append(opt.qualifiers, qualifiers...)
+	}
+}
+
+// WithProvideCloseTimeout 实例销毁的超时时间，超时后不再等待实例的销毁，继续销毁其余的实例
+func WithProvideCloseTimeout(timeout time.Duration) ProvideOption {
+	return func(opt *provideOptions) {
+		opt.closeTimeout = timeout
+	}
+}
+
 type provideOptions struct {
-	priority int
+	priority     int
+	primary      bool
+	qualifiers   []string
+	setters      map[string]string
+	conditions   []Condition
+	closeTimeout time.Duration
+}
+
+func (opt *provideOptions) object(value interface{}, name string) *object {
+	return &object{
+		priority:     opt.priority,
+		value:        value,
+		name:         name,
+		primary:      opt.primary,
+		qualifiers:   opt.qualifiers,
+		setters:      opt.setters,
+		closeTimeout: opt.closeTimeout,
+	}
 }
 
 func apply(opts ...ProvideOption) *provideOptions {
@@ -77,89 +141,66 @@ func apply(opts ...ProvideOption) *provideOptions {
 	return opt
 }
 
+type InitOption func(opt *initOptions)
+
+// WithInitParallel 按照依赖关系的层级并发初始化实例，同一层级的实例按照优先级（从大到小）开始初始化
+// @param workers int 最大并发数，小于等于 0 时不限制
+func WithInitParallel(workers int) InitOption {
+	return func(opt *initOptions) {
+		opt.parallel = true
+		opt.workers = workers
+	}
+}
+
+type initOptions struct {
+	parallel bool
+	workers  int
+}
+
+func applyInit(opts ...InitOption) *initOptions {
+	opt := &initOptions{}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	return opt
+}
+
 // ProvideByName 通过命名注入实例
 // @param name string "命名"
 // @param value interface 实例
 func ProvideByName(name string, value interface{}, opts ...ProvideOption) {
-	opt := apply(opts...)
-	if err := g.provide(&object{
-		priority: opt.priority,
-		value:    value,
-		name:     name,
-	}); err != nil {
-		panic(err)
-	}
+	defaultContainer.ProvideByName(name, value, opts...)
 }
 
 // ObtainByName 通过命名获取实例
 // @param name string "命名"
 // @return 指定名字的实例
 func ObtainByName(name string) interface{} {
-	if obj, ok := g.named[name]; ok {
-		return obj.value
-	}
-	panic(fmt.Sprintf("ginjects: not found name `%s` instance", name))
+	return defaultContainer.ObtainByName(name)
 }
 
 // ProvideByValue
 // @description 通过实例类型注入实例
 // @param value 实例
 func ProvideByValue(value interface{}, opts ...ProvideOption) {
-	opt := apply(opts...)
-	if err := g.provide(&object{
-		priority: opt.priority,
-		value:    value,
-	}); err != nil {
-		panic(err)
-	}
+	defaultContainer.ProvideByValue(value, opts...)
+}
+
+// ProvideByConstructor
+// @description 通过构造函数注入实例，构造函数的参数从依赖中获取，返回值作为实例注入（Init 时调用）
+// 构造函数在依赖注入之前调用：参数的字段还没有注入，也没有初始化，构造函数只应该保存参数，在返回值的初始化方法中使用（返回值在参数之后初始化）
+// @param constructor 构造函数，如：func(db *DB, cfg *Config) (*Repo, error)
+func ProvideByConstructor(constructor interface{}, opts ...ProvideOption) {
+	defaultContainer.ProvideByConstructor(constructor, opts...)
 }
 
 // ObtainByType 通过值的类型（可以是interface或struct）获取实例
 // @param value interface "值（类型可以是interface或struct）"
 // @return 匹配值的类型的实例
 func ObtainByType(value interface{}) interface{} {
-	typ := reflect.TypeOf(value)
-	if typ == nil {
-		panic("ginjects: the value must be a reference pointer")
-	}
-	var realVal interface{}
-	if typ.Kind() == reflect.Ptr {
-		elemTyp := typ.Elem()
-		switch elemTyp.Kind() {
-		case reflect.Interface:
-			var assignableTypes []string
-			for tp, val := range g.unnamedType {
-				if tp.AssignableTo(elemTyp) {
-					assignableTypes = append(assignableTypes, tp.String())
-					realVal = val
-				}
-			}
-			if realVal == nil {
-				panic(fmt.Sprintf("ginjects: not found type `%s` instance or implement type `%s`", elemTyp.String(), elemTyp.String()))
-			}
-			if len(assignableTypes) > 1 {
-				panic(fmt.Sprintf("ginjects: exist type `%v` instance or implement type `%s`", assignableTypes, elemTyp.String()))
-			}
-		case reflect.Struct:
-			if val, ok := g.unnamedType[typ]; ok {
-				realVal = val
-			} else {
-				panic(fmt.Sprintf("ginjects: not found type `%s` instance", typ.String()))
-			}
-		default:
-			panic("ginjects: the value must be a reference pointer to struct or interface")
-		}
-	}
-	if realVal != nil {
-		return realVal
-	}
-	panic("ginjects: the value must be a reference pointer")
+	return defaultContainer.ObtainByType(value)
 }
 
 func PrintObjects() []string {
-	var r []string
-	for _, o := range g.objects() {
-		r = append(r, o.String())
-	}
-	return r
+	return defaultContainer.PrintObjects()
 }
@@ -96,7 +96,7 @@ func Test_Inject(t *testing.T) {
 	c := &C{}
 	ProvideByValue(c)
 
-	Init()
+	ensure.Nil(t, Init())
 
 	ensure.SameElements(t, PrintObjects(), []string{"\"*ginjects.B named b\"", "\"*ginjects.B\"", "\"*ginjects.A\"", "\"*errors.errorString\"", "\"*ginjects.A\"", "\"*ginjects.A\"", "\"*ginjects.C\""})
 
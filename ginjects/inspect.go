@@ -0,0 +1,107 @@
+package ginjects
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Resolution 字段注入的实例的获取方式
+type Resolution string
+
+const (
+	ResolvedByType       Resolution = "type"       // 按照类型（Struct 指针）获取的实例
+	ResolvedByName       Resolution = "name"       // 按照命名获取的实例
+	ResolvedByInterface  Resolution = "interface"  // 按照 interface 获取的实例
+	ResolvedPrivate      Resolution = "private"    // 创建的私有实例（inject:"private"）
+	ResolvedCreated      Resolution = "created"    // 没有找到实例时创建的实例
+	ResolvedByCollection Resolution = "collection" // 集合注入的实例
+	ResolvedByRef        Resolution = "ref"        // Ref 字段绑定的命名实例
+)
+
+// ObjectInfo 实例的注入信息
+type ObjectInfo struct {
+	Object     string       `json:"object"` // 实例的描述，如："*pkg.DB named db"
+	Type       string       `json:"type"`
+	Name       string       `json:"name,omitempty"`
+	InitOrder  int          `json:"init_order"`  // 初始化的顺序，从 1 开始，0 为没有初始化
+	CloseOrder int          `json:"close_order"` // 销毁的顺序，从 1 开始，0 为没有初始化
+	Fields     []*FieldInfo `json:"fields,omitempty"`
+}
+
+// FieldInfo 字段（或者构造函数的参数）注入的实例
+type FieldInfo struct {
+	Field      string     `json:"field"`    // 字段名，集合注入的元素如：Handlers[0]、Handlers[name]，构造函数的参数如：arg0
+	Provider   string     `json:"provider"` // 注入的实例的描述
+	Resolution Resolution `json:"resolution"`
+	Inherited  bool       `json:"inherited,omitempty"` // 注入的实例属于父容器
+}
+
+// Inspect 返回容器（不包括父容器）的实例以及每个实例的字段注入的实例，按照注入的顺序排序，需要在 Init 之后调用
+// 可以用于测试断言，或者序列化为 JSON 用于调试，可以与 Close、Replace 并发调用
+func (c *Container) Inspect() []*ObjectInfo {
+	c.mu.Lock()
+	inited := append([]*object{}, c.inited...)
+	c.mu.Unlock()
+	order := make(map[*object]int, len(inited))
+	for i, o := range inited {
+		order[o] = i + 1
+	}
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	return c.g.inspect(order)
+}
+
+// Inspect 返回默认容器的实例以及每个实例的字段注入的实例
+func Inspect() []*ObjectInfo {
+	return defaultContainer.Inspect()
+}
+
+func (g *graph) inspect(order map[*object]int) []*ObjectInfo {
+	indexes := make([]int, 0, len(g.index2Object))
+	for index := range g.index2Object {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	infos := make([]*ObjectInfo, 0, len(indexes))
+	for _, index := range indexes {
+		o := g.index2Object[index]
+		info := &ObjectInfo{
+			Object: o.String(),
+			Type:   o.reflectType.String(),
+			Name:   o.name,
+		}
+		if i, ok := order[o]; ok {
+			info.InitOrder = i
+			info.CloseOrder = len(order) - i + 1
+		}
+
+		fieldNames := make([]string, 0, len(o.fields))
+		for fieldName := range o.fields {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+		for _, fieldName := range fieldNames {
+			info.Fields = append(info.Fields, g.fieldInfo(fieldName, o.fields[fieldName], o.resolutions[fieldName]))
+		}
+		for i, arg := range o.args {
+			resolution := ResolvedByType
+			if o.constructor.fn.Type().In(i).Kind() == reflect.Interface {
+				resolution = ResolvedByInterface
+			}
+			info.Fields = append(info.Fields, g.fieldInfo(fmt.Sprintf("arg%d", i), arg, resolution))
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func (g *graph) fieldInfo(field string, dep *object, resolution Resolution) *FieldInfo {
+	return &FieldInfo{
+		Field:      field,
+		Provider:   dep.String(),
+		Resolution: resolution,
+		Inherited:  g.index2Object[dep.index] != dep,
+	}
+}
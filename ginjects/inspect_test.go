@@ -0,0 +1,84 @@
+package ginjects
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForInspect struct {
+	Named    *TypeAnswerStruct          `inject:"answer"`
+	Handler  TypeForCollectionHandler   `inject:""`
+	Handlers []TypeForCollectionHandler `inject:""`
+	Counter  *TypeForContainerCounter   `inject:""`
+	Private  *TypeForContainerCounter   `inject:"private"`
+}
+
+type TypeForInspectRepo struct{}
+
+func TestInspect(t *testing.T) {
+	parent := NewContainer()
+	parent.ProvideByValue(&TypeForCollectionHandlerA{})
+	c := parent.NewChild()
+	c.ProvideByName("answer", &TypeAnswerStruct{})
+	c.ProvideByValue(&TypeForInspect{})
+	c.ProvideByConstructor(func(h TypeForCollectionHandler, a *TypeForInspect) *TypeForInspectRepo {
+		return &TypeForInspectRepo{}
+	})
+	ensure.Nil(t, c.Init())
+
+	infos := c.Inspect()
+	byObject := make(map[string]*ObjectInfo)
+	for _, info := range infos {
+		byObject[info.Object] = info
+	}
+	ensure.DeepEqual(t, len(infos), 5)
+
+	v := byObject[`"*ginjects.TypeForInspect"`]
+	ensure.DeepEqual(t, v.Fields, []*FieldInfo{
+		{Field: "Counter", Provider: `"*ginjects.TypeForContainerCounter"`, Resolution: ResolvedCreated},
+		{Field: "Handler", Provider: `"*ginjects.TypeForCollectionHandlerA"`, Resolution: ResolvedByInterface, Inherited: true},
+		{Field: "Handlers[0]", Provider: `"*ginjects.TypeForCollectionHandlerA"`, Resolution: ResolvedByCollection, Inherited: true},
+		{Field: "Named", Provider: `"*ginjects.TypeAnswerStruct named answer"`, Resolution: ResolvedByName},
+		{Field: "Private", Provider: `"*ginjects.TypeForContainerCounter"`, Resolution: ResolvedPrivate},
+	})
+
+	repo := byObject[`"*ginjects.TypeForInspectRepo"`]
+	ensure.DeepEqual(t, repo.Fields, []*FieldInfo{
+		{Field: "arg0", Provider: `"*ginjects.TypeForCollectionHandlerA"`, Resolution: ResolvedByInterface, Inherited: true},
+		{Field: "arg1", Provider: `"*ginjects.TypeForInspect"`, Resolution: ResolvedByType},
+	})
+
+	// The dependencies are initialized first and closed last.
+	answer := byObject[`"*ginjects.TypeAnswerStruct named answer"`]
+	ensure.True(t, answer.InitOrder < v.InitOrder)
+	ensure.True(t, v.InitOrder < repo.InitOrder)
+	ensure.DeepEqual(t, repo.CloseOrder, 1)
+	ensure.DeepEqual(t, v.InitOrder+v.CloseOrder, len(infos)+1)
+
+	_, err := json.Marshal(infos)
+	ensure.Nil(t, err)
+
+	c.Close()
+	for _, info := range c.Inspect() {
+		ensure.DeepEqual(t, info.InitOrder, 0)
+	}
+}
+
+func TestInspectConcurrentClose(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForContainerCounter{})
+	ensure.Nil(t, c.Init())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			for _, info := range c.Inspect() {
+				ensure.True(t, info.InitOrder <= 1)
+			}
+		}
+	}()
+	c.Close()
+	<-done
+}
@@ -0,0 +1,249 @@
+package ginjects
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// lazyField is implemented by the Lazy and Provider field types, the graph
+// binds them to a resolver instead of injecting the dependency.
+type lazyField interface {
+	bind(resolve func() (interface{}, error))
+	elemType() reflect.Type
+}
+
+var lazyFieldType = reflect.TypeOf((*lazyField)(nil)).Elem()
+
+func isLazyField(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(lazyFieldType)
+}
+
+// Lazy 延迟注入，第一次调用 Get 时从容器获取实例，之后返回同一个实例
+// 不会增加实例之间的依赖关系，可以用于打破循环依赖
+// 支持的标签与普通字段一致：
+//
+//	`inject:""`          按照类型（Struct 指针或 interface）获取实例
+//	`inject:"dev logger"` 按照命名获取实例
+//	`inject:"qualifier=mysql"` 按照类型以及限定符获取实例
+//	`inject:"private"`    创建新的私有实例（完成依赖注入与初始化）
+type Lazy[T any] struct {
+	mu      sync.Mutex
+	resolve func() (interface{}, error)
+	done    bool
+	value   T
+}
+
+func (l *Lazy[T]) bind(resolve func() (interface{}, error)) {
+	l.resolve = resolve
+}
+
+func (l *Lazy[T]) elemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get 获取实例，失败时下一次调用会重新获取
+func (l *Lazy[T]) Get() (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.done {
+		return l.value, nil
+	}
+	value, err := resolveLazy[T](l.resolve)
+	if err != nil {
+		return value, err
+	}
+	l.value, l.done = value, true
+	return value, nil
+}
+
+// MustGet 获取实例，失败时 panic
+func (l *Lazy[T]) MustGet() T {
+	value, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Provider 每次调用 Get 时从容器获取实例
+// 标签为 `inject:"private"` 时（prototype），每次调用 Get 都会创建新的实例，并完成依赖注入与初始化
+// 创建的实例（包括其私有的依赖）在容器 Close 时销毁，按照创建的逆向顺序，在容器的实例之前
+// 其他标签与 Lazy 一致
+type Provider[T any] struct {
+	resolve func() (interface{}, error)
+}
+
+func (p *Provider[T]) bind(resolve func() (interface{}, error)) {
+	p.resolve = resolve
+}
+
+func (p *Provider[T]) elemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get 获取实例
+func (p *Provider[T]) Get() (T, error) {
+	return resolveLazy[T](p.resolve)
+}
+
+// MustGet 获取实例，失败时 panic
+func (p *Provider[T]) MustGet() T {
+	value, err := p.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func resolveLazy[T any](resolve func() (interface{}, error)) (T, error) {
+	var zero T
+	if resolve == nil {
+		return zero, fmt.Errorf("ginjects: lazy field of type %s was not injected", reflect.TypeOf((*T)(nil)).Elem())
+	}
+	value, err := resolve()
+	if err != nil {
+		return zero, err
+	}
+	return value.(T), nil
+}
+
+// lazyResolver returns the function resolving the dependency of a Lazy or
+// Provider field from the graph.
+func (g *graph) lazyResolver(o *object, fieldName string, elemType reflect.Type, t *tag) (func() (interface{}, error), error) {
+	if t.Inline {
+		return nil, fmt.Errorf(
+			"ginjects: inline requested on lazy field %s in type %s",
+			fieldName,
+			o.reflectType,
+		)
+	}
+
+	if t.Name != "" {
+		return func() (interface{}, error) {
+			existing, err := g.findLazyNamed(o, fieldName, elemType, t)
+			if err != nil {
+				return nil, err
+			}
+			return g.decorateValue(existing, elemType)
+		}, nil
+	}
+
+	if t.Private {
+		if !isStructPtr(elemType) {
+			return nil, fmt.Errorf(
+				"ginjects: private lazy field %s in type %s must be of a pointer to a struct",
+				fieldName,
+				o.reflectType,
+			)
+		}
+		return func() (interface{}, error) {
+			created := &object{value: reflect.New(elemType.Elem()).Interface(), private: true, created: true}
+			c := &Container{g: newGraph(g)}
+			if err := c.g.provide(created); err != nil {
+				return nil, err
+			}
+			if err := c.Init(); err != nil {
+				return nil, err
+			}
+			g.trackPrivate(c)
+			return c.g.decorateValue(created, elemType)
+		}, nil
+	}
+
+	if !isStructPtr(elemType) && elemType.Kind() != reflect.Interface {
+		return nil, fmt.Errorf(
+			"ginjects: found inject tag on unsupported lazy field %s in type %s",
+			fieldName,
+			o.reflectType,
+		)
+	}
+	return func() (interface{}, error) {
+		found, err := g.findLazyCandidate(o, fieldName, elemType, t)
+		if err != nil {
+			return nil, err
+		}
+		return g.decorateValue(found, elemType)
+	}, nil
+}
+
+// findLazyNamed returns the named object of the lazy field, the types are
+// checked under valueMu as Replace may change them.
+func (g *graph) findLazyNamed(o *object, fieldName string, elemType reflect.Type, t *tag) (*object, error) {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	existing := g.findNamed(t.Name)
+	if existing == nil {
+		return nil, fmt.Errorf(
+			"ginjects: did not find object named %s required by lazy field %s in type %s",
+			t.Name,
+			fieldName,
+			o.reflectType,
+		)
+	}
+	if !existing.reflectType.AssignableTo(elemType) {
+		return nil, fmt.Errorf(
+			"ginjects: object named %s of type %s is not assignable to lazy field %s (%s) in type %s",
+			t.Name,
+			existing.reflectType,
+			fieldName,
+			elemType,
+			o.reflectType,
+		)
+	}
+	return existing, nil
+}
+
+// findLazyCandidate returns the only object assignable to the lazy field.
+func (g *graph) findLazyCandidate(o *object, fieldName string, elemType reflect.Type, t *tag) (*object, error) {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	found := g.findCandidates(elemType, t.Qualifier)
+	if len(found) > 1 {
+		return nil, fmt.Errorf(
+			"ginjects: found two assignable values for lazy field %s in type %s. one type %s and another type %s",
+			fieldName,
+			o.reflectType,
+			found[0].reflectType,
+			found[1].reflectType,
+		)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf(
+			"ginjects: found no assignable value for lazy field %s in type %s",
+			fieldName,
+			o.reflectType,
+		)
+	}
+	return found[0], nil
+}
+
+// trackPrivate keeps the container of a private instance to close it with the
+// container of the graph, only if it has objects to close.
+func (g *graph) trackPrivate(c *Container) {
+	c.mu.Lock()
+	closable := false
+	for _, obj := range c.inited {
+		closable = closable || obj.closeFn != nil
+	}
+	c.mu.Unlock()
+	c.g.privatesMu.Lock()
+	closable = closable || len(c.g.privates) > 0
+	c.g.privatesMu.Unlock()
+	if !closable {
+		return
+	}
+	g.privatesMu.Lock()
+	defer g.privatesMu.Unlock()
+	g.privates = append(g.privates, c)
+}
+
+// takePrivates returns the containers of the private instances created so far,
+// they are no longer tracked.
+func (g *graph) takePrivates() []*Container {
+	g.privatesMu.Lock()
+	defer g.privatesMu.Unlock()
+	privates := g.privates
+	g.privates = nil
+	return privates
+}
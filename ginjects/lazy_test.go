@@ -0,0 +1,120 @@
+package ginjects
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForLazyA struct {
+	B *TypeForLazyB `inject:""`
+}
+
+type TypeForLazyB struct {
+	A      Lazy[*TypeForLazyA] `inject:""`
+	Named  Lazy[Answerable]    `inject:"answer"`
+	Answer Provider[*TypeAnswerStruct]
+}
+
+type TypeForLazyRequest struct {
+	A      *TypeForLazyA `inject:""`
+	inited bool
+}
+
+func (r *TypeForLazyRequest) Init() {
+	r.inited = true
+}
+
+type TypeForLazyHandler struct {
+	Request Provider[*TypeForLazyRequest] `inject:"private"`
+	Missing Lazy[*TypeForLazyRequest]     `inject:""`
+}
+
+func TestLazyBreaksCycle(t *testing.T) {
+	c := NewContainer()
+	a := &TypeForLazyA{}
+	answer := &TypeAnswerStruct{answer: 42}
+	c.ProvideByValue(a)
+	c.ProvideByName("answer", answer)
+	ensure.Nil(t, c.Init())
+
+	ensure.True(t, a.B.A.MustGet() == a)
+	ensure.True(t, a.B.A.MustGet() == a)
+	ensure.DeepEqual(t, a.B.Named.MustGet().Answer(), 42)
+
+	// not tagged, so not injected.
+	_, err := a.B.Answer.Get()
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: lazy field of type *ginjects.TypeAnswerStruct was not injected")
+}
+
+func TestProviderPrototype(t *testing.T) {
+	c := NewContainer()
+	a := &TypeForLazyA{}
+	h := &TypeForLazyHandler{}
+	c.ProvideByValue(a)
+	c.ProvideByValue(h)
+	ensure.Nil(t, c.Init())
+
+	r1 := h.Request.MustGet()
+	r2 := h.Request.MustGet()
+	ensure.True(t, r1 != r2)
+	ensure.True(t, r1.A == a)
+	ensure.True(t, r2.A == a)
+	ensure.True(t, r1.inited)
+	ensure.True(t, r2.inited)
+
+	_, err := h.Missing.Get()
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: found no assignable value for lazy field Missing in type *ginjects.TypeForLazyHandler")
+}
+
+func TestLazyMissingNamed(t *testing.T) {
+	var v struct {
+		A Lazy[*TypeAnswerStruct] `inject:"foo"`
+	}
+	ensure.Nil(t, populate(&v))
+	_, err := v.A.Get()
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: did not find object named foo required by lazy field A in type *struct { A ginjects.Lazy[*github.com/erkesi/gobean/ginjects.TypeAnswerStruct] \"inject:\\\"foo\\\"\" }")
+}
+
+func TestLazyUnsupported(t *testing.T) {
+	var v struct {
+		A Provider[int] `inject:""`
+	}
+	err := populate(&v)
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, err.Error(), "ginjects: found inject tag on unsupported lazy field A in type *struct { A ginjects.Provider[int] \"inject:\\\"\\\"\" }")
+}
+
+type TypeForLazySession struct {
+	DB *TypeForLifecycleDB `inject:""`
+	id int
+}
+
+func (s *TypeForLazySession) Close() {
+	s.DB.Recorder.records = append(s.DB.Recorder.records, fmt.Sprintf("session%d.close", s.id))
+}
+
+type TypeForLazySessionFactory struct {
+	Session Provider[*TypeForLazySession] `inject:"private"`
+}
+
+func TestProviderPrototypeClose(t *testing.T) {
+	c := NewContainer()
+	recorder := &TypeForLifecycleRecorder{}
+	f := &TypeForLazySessionFactory{}
+	c.ProvideByValue(recorder)
+	c.ProvideByValue(&TypeForLifecycleDB{})
+	c.ProvideByValue(f)
+	ensure.Nil(t, c.Init())
+
+	f.Session.MustGet().id = 1
+	f.Session.MustGet().id = 2
+	// The sessions are closed before the objects they depend on.
+	ensure.Nil(t, c.CloseContext(context.Background()))
+	ensure.DeepEqual(t, recorder.records, []string{"db.init", "session2.close", "session1.close", "db.close"})
+}
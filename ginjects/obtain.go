@@ -0,0 +1,120 @@
+package ginjects
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNotFound 找不到实例
+var ErrNotFound = errors.New("ginjects: not found instance")
+
+// AmbiguousError 多个实例匹配同一个类型
+type AmbiguousError struct {
+	Type       reflect.Type
+	Candidates []string // 匹配的实例，如："*pkg.MysqlRepo"
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("ginjects: found %d assignable instances for type `%s`: %s, "+
+		"use ginjects.WithProvidePrimary or ginjects.WithProvideQualifier to choose one",
+		len(e.Candidates), e.Type, strings.Join(e.Candidates, ", "))
+}
+
+// Obtain 通过类型（Struct 指针或 interface）从默认容器获取实例
+// @return T 实例
+// @return error 找不到实例时为 ErrNotFound，多个实例匹配时为 *AmbiguousError
+func Obtain[T any]() (T, error) {
+	return ObtainFrom[T](defaultContainer)
+}
+
+// MustObtain 同 Obtain，失败时 panic
+func MustObtain[T any]() T {
+	return MustObtainFrom[T](defaultContainer)
+}
+
+// ObtainNamed 通过命名从默认容器获取实例
+// @param name string "命名"
+// @return error 找不到实例时为 ErrNotFound
+func ObtainNamed[T any](name string) (T, error) {
+	return ObtainNamedFrom[T](defaultContainer, name)
+}
+
+// ObtainAll 从默认容器获取所有匹配类型（Struct 指针或 interface）的实例（包括命名实例），按照优先级（从大到小）排序
+func ObtainAll[T any]() ([]T, error) {
+	return ObtainAllFrom[T](defaultContainer)
+}
+
+// ObtainFrom 同 Obtain，从指定的容器获取实例
+func ObtainFrom[T any](c *Container) (T, error) {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	var zero T
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if err := checkObtainType(typ); err != nil {
+		return zero, err
+	}
+	found := c.g.findCandidates(typ, "")
+	if len(found) == 0 {
+		return zero, fmt.Errorf("%w of type `%s`", ErrNotFound, typ)
+	}
+	if len(found) > 1 {
+		candidates := make([]string, 0, len(found))
+		for _, o := range found {
+			candidates = append(candidates, o.String())
+		}
+		return zero, &AmbiguousError{Type: typ, Candidates: candidates}
+	}
+	return found[0].value.(T), nil
+}
+
+// MustObtainFrom 同 ObtainFrom，失败时 panic
+func MustObtainFrom[T any](c *Container) T {
+	value, err := ObtainFrom[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// ObtainNamedFrom 同 ObtainNamed，从指定的容器获取实例
+func ObtainNamedFrom[T any](c *Container, name string) (T, error) {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	var zero T
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	existing := c.g.findNamed(name)
+	if existing == nil {
+		return zero, fmt.Errorf("%w named `%s`", ErrNotFound, name)
+	}
+	value, ok := existing.value.(T)
+	if !ok {
+		return zero, fmt.Errorf("ginjects: instance named `%s` of type `%s` is not assignable to type `%s`",
+			name, existing.reflectType, typ)
+	}
+	return value, nil
+}
+
+// ObtainAllFrom 同 ObtainAll，从指定的容器获取实例
+func ObtainAllFrom[T any](c *Container) ([]T, error) {
+	valueMu.RLock()
+	defer valueMu.RUnlock()
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if err := checkObtainType(typ); err != nil {
+		return nil, err
+	}
+	found := c.g.findCollection(typ, nil)
+	values := make([]T, 0, len(found))
+	for _, o := range found {
+		values = append(values, o.value.(T))
+	}
+	return values, nil
+}
+
+func checkObtainType(typ reflect.Type) error {
+	if !isStructPtr(typ) && typ.Kind() != reflect.Interface {
+		return fmt.Errorf("ginjects: obtain type `%s` must be a pointer to a struct or an interface", typ)
+	}
+	return nil
+}
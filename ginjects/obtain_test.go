@@ -0,0 +1,68 @@
+package ginjects
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func newObtainContainer(t *testing.T) *Container {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForCollectionHandlerA{}, WithProvidePriority(1))
+	c.ProvideByValue(&TypeForCollectionHandlerB{}, WithProvidePriority(2))
+	c.ProvideByName("answer", &TypeAnswerStruct{answer: 42})
+	ensure.Nil(t, c.Init())
+	return c
+}
+
+func TestObtain(t *testing.T) {
+	c := newObtainContainer(t)
+
+	a, err := ObtainFrom[*TypeForCollectionHandlerA](c)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, a.Handle(), "a")
+
+	_, err = ObtainFrom[*TypeAnswerStruct](c)
+	ensure.True(t, errors.Is(err, ErrNotFound))
+	ensure.DeepEqual(t, err.Error(), "ginjects: not found instance of type `*ginjects.TypeAnswerStruct`")
+
+	_, err = ObtainFrom[TypeForCollectionHandler](c)
+	var ambiguousErr *AmbiguousError
+	ensure.True(t, errors.As(err, &ambiguousErr))
+	ensure.DeepEqual(t, ambiguousErr.Candidates, []string{"\"*ginjects.TypeForCollectionHandlerA\"", "\"*ginjects.TypeForCollectionHandlerB\""})
+
+	_, err = ObtainFrom[int](c)
+	ensure.DeepEqual(t, err.Error(), "ginjects: obtain type `int` must be a pointer to a struct or an interface")
+
+	ensure.DeepEqual(t, MustObtainFrom[*TypeForCollectionHandlerB](c).Handle(), "b")
+}
+
+func TestObtainNamed(t *testing.T) {
+	c := newObtainContainer(t)
+
+	answer, err := ObtainNamedFrom[Answerable](c, "answer")
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, answer.Answer(), 42)
+
+	_, err = ObtainNamedFrom[Answerable](c, "foo")
+	ensure.True(t, errors.Is(err, ErrNotFound))
+	ensure.DeepEqual(t, err.Error(), "ginjects: not found instance named `foo`")
+
+	_, err = ObtainNamedFrom[TypeForCollectionHandler](c, "answer")
+	ensure.DeepEqual(t, err.Error(), "ginjects: instance named `answer` of type `*ginjects.TypeAnswerStruct` is not assignable to type `ginjects.TypeForCollectionHandler`")
+}
+
+func TestObtainAll(t *testing.T) {
+	c := newObtainContainer(t)
+
+	handlers, err := ObtainAllFrom[TypeForCollectionHandler](c)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, len(handlers), 2)
+	ensure.DeepEqual(t, handlers[0].Handle(), "b")
+	ensure.DeepEqual(t, handlers[1].Handle(), "a")
+
+	answers, err := ObtainAllFrom[Answerable](c)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, len(answers), 1)
+}
@@ -0,0 +1,108 @@
+package ginjects
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Copy 复制容器（包括父容器）注入的实例以及构造函数，Struct 实例为浅拷贝，需要在 Init 之前调用
+// 用于测试：每个测试用例复制一份容器，替换部分实例后 Init，不影响原来的容器
+func (c *Container) Copy() *Container {
+	copied := &Container{g: c.g.clone()}
+	for cur, parent := copied, c.parent; parent != nil; cur, parent = cur.parent, parent.parent {
+		cur.parent = &Container{g: cur.g.parent}
+	}
+	return copied
+}
+
+// Copy 复制默认容器
+func Copy() *Container {
+	return defaultContainer.Copy()
+}
+
+// OverrideByType 替换可以赋值给 typ（Struct 指针或 interface）的实例，需要在 Init 之前调用，用于测试
+// 删除容器中可以赋值给 typ 的实例（不包括命名实例）以及返回值可以赋值给 typ 的构造函数，value 作为 primary 实例注入
+// @param typ reflect.Type "如：reflect.TypeOf((*Repo)(nil)).Elem()"
+// @param value interface "替换的实例，如：gomock 生成的 mock"
+func (c *Container) OverrideByType(typ reflect.Type, value interface{}, opts ...ProvideOption) {
+	if err := c.g.overrideByType(typ, value, apply(opts...)); err != nil {
+		panic(err)
+	}
+}
+
+// OverrideByName 替换命名的实例，需要在 Init 之前调用，用于测试
+// @param name string "命名"
+// @param value interface "替换的实例"
+func (c *Container) OverrideByName(name string, value interface{}, opts ...ProvideOption) {
+	if err := c.g.overrideByName(name, value, apply(opts...)); err != nil {
+		panic(err)
+	}
+}
+
+func (g *graph) overrideByType(typ reflect.Type, value interface{}, opt *provideOptions) error {
+	if g.nodes != nil {
+		return fmt.Errorf("ginjects: override type %s after the graph was populated", typ)
+	}
+	if !isStructPtr(typ) && typ.Kind() != reflect.Interface {
+		return fmt.Errorf("ginjects: override type %s must be a pointer to a struct or an interface", typ)
+	}
+	if value == nil || !reflect.TypeOf(value).AssignableTo(typ) {
+		return fmt.Errorf("ginjects: override value of type %T is not assignable to type %s", value, typ)
+	}
+	for _, o := range g.assignable(typ) {
+		g.remove(o)
+	}
+	constructors := g.constructors[:0]
+	for _, c := range g.constructors {
+		if !c.provides(typ) {
+			constructors = append(constructors, c)
+		}
+	}
+	g.constructors = constructors
+	g.removeConditionals(func(c *conditional) bool {
+		return c.provides(typ)
+	})
+
+	o := opt.object(value, "")
+	o.primary = true
+	return g.provide(o)
+}
+
+func (g *graph) overrideByName(name string, value interface{}, opt *provideOptions) error {
+	if g.nodes != nil {
+		return fmt.Errorf("ginjects: override object named %s after the graph was populated", name)
+	}
+	if existing := g.named[name]; existing != nil {
+		g.remove(existing)
+	}
+	g.removeConditionals(func(c *conditional) bool {
+		return c.object != nil && c.object.name == name
+	})
+	return g.provide(opt.object(value, name))
+}
+
+// remove the object from the graph, it must not have been populated.
+func (g *graph) remove(o *object) {
+	if o.name != "" {
+		delete(g.named, o.name)
+	} else {
+		unnamed := g.unnamed[:0]
+		for _, existing := range g.unnamed {
+			if existing != o {
+				unnamed = append(unnamed, existing)
+			}
+		}
+		g.unnamed = unnamed
+		if !o.private {
+			delete(g.unnamedType, o.reflectType)
+		}
+	}
+	delete(g.index2Object, o.index)
+	allNodes := g.allNodes[:0]
+	for _, n := range g.allNodes {
+		if n.index != o.index {
+			allNodes = append(allNodes, n)
+		}
+	}
+	g.allNodes = allNodes
+}
@@ -0,0 +1,79 @@
+package ginjects
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForOverrideAnswer struct{}
+
+func (a *TypeForOverrideAnswer) Answer() int {
+	return 1
+}
+
+type TypeForOverrideUser struct {
+	Answer   Answerable         `inject:""`
+	Named    Answerable         `inject:"answer"`
+	Recorder *TypeForOverrideDB `inject:""`
+}
+
+type TypeForOverrideDB struct {
+	inited bool
+}
+
+func (db *TypeForOverrideDB) Init() {
+	db.inited = true
+}
+
+func TestCopy(t *testing.T) {
+	parent := NewContainer()
+	parent.ProvideByName("answer", &TypeAnswerStruct{answer: 42})
+	c := parent.NewChild()
+	c.ProvideByConstructor(func() Answerable {
+		return &TypeAnswerStruct{answer: 1}
+	})
+	user := &TypeForOverrideUser{}
+	c.ProvideByValue(user)
+
+	copied := c.Copy()
+	ensure.Nil(t, copied.Init())
+	copiedUser := MustObtainFrom[*TypeForOverrideUser](copied)
+	ensure.True(t, copiedUser != user)
+	ensure.DeepEqual(t, copiedUser.Answer.Answer(), 1)
+	ensure.DeepEqual(t, copiedUser.Named.Answer(), 42)
+	ensure.True(t, copiedUser.Recorder.inited)
+	ensure.True(t, user.Answer == nil)
+}
+
+func TestOverride(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByName("answer", &TypeAnswerStruct{answer: 42})
+	c.ProvideByConstructor(func() Answerable {
+		return &TypeAnswerStruct{answer: 1}
+	})
+	c.ProvideByValue(&TypeForOverrideDB{})
+	c.ProvideByValue(&TypeForOverrideUser{})
+
+	db := &TypeForOverrideDB{}
+	c.OverrideByType(reflect.TypeOf((*Answerable)(nil)).Elem(), &TypeForOverrideAnswer{})
+	c.OverrideByType(reflect.TypeOf(db), db)
+	c.OverrideByName("answer", &TypeAnswerStruct{answer: 7})
+	ensure.Nil(t, c.Init())
+
+	user := MustObtainFrom[*TypeForOverrideUser](c)
+	ensure.DeepEqual(t, user.Answer, Answerable(&TypeForOverrideAnswer{}))
+	ensure.DeepEqual(t, user.Named.Answer(), 7)
+	ensure.True(t, user.Recorder == db)
+	ensure.True(t, db.inited)
+}
+
+func TestOverrideErrors(t *testing.T) {
+	c := NewContainer()
+	ensure.Err(t, c.g.overrideByType(reflect.TypeOf(0), 1, apply()), regexp.MustCompile("must be a pointer to a struct or an interface"))
+	ensure.Err(t, c.g.overrideByType(reflect.TypeOf(&TypeForOverrideDB{}), &TypeAnswerStruct{}, apply()), regexp.MustCompile("is not assignable to type"))
+	ensure.Nil(t, c.Init())
+	ensure.Err(t, c.g.overrideByName("answer", &TypeAnswerStruct{}, apply()), regexp.MustCompile("after the graph was populated"))
+}
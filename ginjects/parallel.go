@@ -0,0 +1,110 @@
+package ginjects
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/erkesi/gobean/glogs"
+	"github.com/erkesi/gobean/grecovers"
+	"github.com/erkesi/gobean/gshares"
+)
+
+// levels groups the objects by their depth in the dependency graph. Objects
+// of the same level don't depend on each other, and are ordered by priority.
+func (g *graph) levels() [][]*object {
+	deps := make(map[int][]int)
+	for _, edge := range g.edges {
+		deps[edge[1].index] = append(deps[edge[1].index], edge[0].index)
+	}
+	depth := make(map[int]int, len(g.nodes))
+	var levels [][]*object
+	// nodes are sorted, so the dependencies of a node are visited before it.
+	for _, node := range g.nodes {
+		d := 0
+		for _, dep := range deps[node.index] {
+			if depth[dep]+1 > d {
+				d = depth[dep] + 1
+			}
+		}
+		depth[node.index] = d
+		o := g.index2Object[node.index]
+		if o.embedded {
+			continue
+		}
+		for len(levels) <= d {
+			levels = append(levels, nil)
+		}
+		levels[d] = append(levels[d], o)
+	}
+	return levels
+}
+
+type initResult struct {
+	obj   *object
+	start time.Time
+	cost  time.Duration
+	err   error
+}
+
+// initObjectsParallel initializes the objects level by level, the objects of a
+// level are initialized concurrently by at most workers goroutines. On failure
+// the level is finished and then the objects already initialized are closed in
+// reverse order.
+func (c *Container) initObjectsParallel(ctx context.Context, workers int) error {
+	var sem chan struct{}
+	if workers > 0 {
+		sem = make(chan struct{}, workers)
+	}
+	for _, level := range c.g.levels() {
+		results := make([]initResult, len(level))
+		var wg sync.WaitGroup
+		for i, obj := range level {
+			if obj.initFn == nil {
+				results[i] = initResult{obj: obj}
+				continue
+			}
+			if sem != nil {
+				sem <- struct{}{}
+			}
+			wg.Add(1)
+			go func(i int, obj *object) {
+				defer func() {
+					if sem != nil {
+						<-sem
+					}
+					wg.Done()
+				}()
+				if glogs.Log != nil {
+					glogs.Log.Debugf(context.TODO(), "ginjects: init inject object(%v)", obj)
+				}
+				start := time.Now()
+				err := grecovers.RecoverOfErr(func() error {
+					return obj.initFn(ctx)
+				})
+				results[i] = initResult{obj: obj, start: start, cost: time.Since(start), err: err}
+			}(i, obj)
+		}
+		wg.Wait()
+
+		var errs Errors
+		for _, result := range results {
+			if result.obj.initFn != nil {
+				c.record("init", result.obj, result.start, result.cost, result.err)
+			}
+			if result.err != nil {
+				errs = append(errs, &ObjectError{Object: result.obj.String(), Op: "init", Err: result.err})
+				continue
+			}
+			c.inited = append(c.inited, result.obj)
+		}
+		if len(errs) > 0 {
+			errs = append(errs, c.closeObjects(gshares.WithoutCancel(ctx))...)
+			if len(errs) == 1 {
+				return errs[0]
+			}
+			return errs
+		}
+	}
+	return nil
+}
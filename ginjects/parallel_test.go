@@ -0,0 +1,108 @@
+package ginjects
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForParallelSlow struct {
+	inited int32
+}
+
+func (s *TypeForParallelSlow) InitContext(ctx context.Context) error {
+	time.Sleep(50 * time.Millisecond)
+	atomic.StoreInt32(&s.inited, 1)
+	return nil
+}
+
+type TypeForParallelSlow1 struct{ TypeForParallelSlow }
+type TypeForParallelSlow2 struct{ TypeForParallelSlow }
+type TypeForParallelSlow3 struct{ TypeForParallelSlow }
+
+type TypeForParallelService struct {
+	Slow1 *TypeForParallelSlow1 `inject:""`
+	Slow2 *TypeForParallelSlow2 `inject:""`
+	Slow3 *TypeForParallelSlow3 `inject:""`
+	ready bool
+}
+
+func (s *TypeForParallelService) InitContext(ctx context.Context) error {
+	s.ready = atomic.LoadInt32(&s.Slow1.inited) == 1 &&
+		atomic.LoadInt32(&s.Slow2.inited) == 1 &&
+		atomic.LoadInt32(&s.Slow3.inited) == 1
+	return nil
+}
+
+func TestGraphLevels(t *testing.T) {
+	g := newGraph(nil)
+	var v TypeForParallelService
+	ensure.Nil(t, g.provide(&object{value: &v}))
+	ensure.Nil(t, g.populate())
+
+	levels := g.levels()
+	ensure.DeepEqual(t, len(levels), 2)
+	ensure.DeepEqual(t, len(levels[0]), 3)
+	ensure.DeepEqual(t, levels[1][0].String(), "\"*ginjects.TypeForParallelService\"")
+}
+
+func TestInitParallel(t *testing.T) {
+	c := NewContainer()
+	var v TypeForParallelService
+	c.ProvideByValue(&v)
+	start := time.Now()
+	ensure.Nil(t, c.Init(WithInitParallel(0)))
+	if cost := time.Since(start); cost >= 150*time.Millisecond {
+		t.Fatalf("expected the slow objects to be initialized concurrently, cost %s", cost)
+	}
+	ensure.True(t, v.ready)
+}
+
+func TestInitParallelWorkers(t *testing.T) {
+	c := NewContainer()
+	var v TypeForParallelService
+	c.ProvideByValue(&v)
+	start := time.Now()
+	ensure.Nil(t, c.Init(WithInitParallel(1)))
+	if cost := time.Since(start); cost < 150*time.Millisecond {
+		t.Fatalf("expected the slow objects to be initialized one by one, cost %s", cost)
+	}
+	ensure.True(t, v.ready)
+}
+
+type TypeForParallelFailed struct {
+	Recorder *TypeForLifecycleRecorder `inject:""`
+}
+
+func (f *TypeForParallelFailed) InitContext(ctx context.Context) error {
+	return errors.New("timeout")
+}
+
+type TypeForParallelPanic struct {
+	Recorder *TypeForLifecycleRecorder `inject:""`
+}
+
+func (p *TypeForParallelPanic) Init() {
+	panic("boom")
+}
+
+func TestInitParallelRollback(t *testing.T) {
+	c := NewContainer()
+	recorder := &TypeForLifecycleRecorder{}
+	c.ProvideByValue(recorder)
+	c.ProvideByValue(&TypeForLifecycleDB{})
+	c.ProvideByValue(&TypeForParallelFailed{})
+	c.ProvideByValue(&TypeForParallelPanic{})
+	err := c.Init(WithInitParallel(2))
+	ensure.NotNil(t, err)
+
+	var errs Errors
+	ensure.True(t, errors.As(err, &errs))
+	ensure.DeepEqual(t, len(errs), 2)
+	ensure.DeepEqual(t, errs[0].Error(), "ginjects: init inject object(\"*ginjects.TypeForParallelFailed\") failed: timeout")
+	ensure.DeepEqual(t, recorder.records, []string{"db.init", "db.close"})
+}
@@ -0,0 +1,204 @@
+package ginjects
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erkesi/gobean/glogs"
+)
+
+// refField is implemented by the Ref field type, the graph binds it to the
+// slot of the named object so the replaced values are seen by the field.
+type refField interface {
+	bindRef(slot *refSlot)
+	elemType() reflect.Type
+}
+
+var refFieldType = reflect.TypeOf((*refField)(nil)).Elem()
+
+// valueMu guards the value fields of the objects rewritten by Replace against
+// the readers at runtime: Obtain, Lazy and Provider fields, Inspect and Health.
+var valueMu sync.RWMutex
+
+func isRefField(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(refFieldType)
+}
+
+// The current value of a named object, shared by the Ref fields bound to it.
+type refSlot struct {
+	value atomic.Value // Holds a refValue
+}
+
+// refValue boxes the value so values of different types, and nil, can be
+// stored in the atomic.Value.
+type refValue struct {
+	value interface{}
+}
+
+func (s *refSlot) load() interface{} {
+	return s.value.Load().(refValue).value
+}
+
+func (s *refSlot) store(value interface{}) {
+	s.value.Store(refValue{value: value})
+}
+
+// Ref 可以替换的命名实例，Container.Replace 替换实例后，Get 返回新的实例，并发安全
+// 只支持标签 `inject:"name"`（按照命名注入），实例可以是任意类型，如：`inject:"rate_limit"` 注入 Ref[int]
+type Ref[T any] struct {
+	slot *refSlot
+}
+
+func (r *Ref[T]) bindRef(slot *refSlot) {
+	r.slot = slot
+}
+
+func (r *Ref[T]) elemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get 获取当前的实例，没有注入时 panic
+func (r *Ref[T]) Get() T {
+	if r.slot == nil {
+		panic(fmt.Sprintf("ginjects: ref field of type %s was not injected", r.elemType()))
+	}
+	value, _ := r.slot.load().(T)
+	return value
+}
+
+// bindRefField binds the Ref field to the slot of the named object.
+func (g *graph) bindRefField(o *object, fieldName string, field reflect.Value, t *tag) error {
+	rf := field.Addr().Interface().(refField)
+	if t.Name == "" || t.Private || t.Inline || t.Qualifier != "" {
+		return fmt.Errorf(
+			"ginjects: ref field %s in type %s must be named",
+			fieldName,
+			o.reflectType,
+		)
+	}
+	existing := g.findNamed(t.Name)
+	if existing == nil {
+		return fmt.Errorf(
+			"ginjects: did not find object named %s required by ref field %s in type %s",
+			t.Name,
+			fieldName,
+			o.reflectType,
+		)
+	}
+	elemType := rf.elemType()
+	if !existing.reflectType.AssignableTo(elemType) {
+		return fmt.Errorf(
+			"ginjects: object named %s of type %s is not assignable to ref field %s (%s) in type %s",
+			t.Name,
+			existing.reflectType,
+			fieldName,
+			elemType,
+			o.reflectType,
+		)
+	}
+	if existing.ref == nil {
+		existing.ref = &refSlot{}
+		existing.ref.store(existing.value)
+	}
+	rf.bindRef(existing.ref)
+	o.addDep(g, fieldName, existing, ResolvedByRef)
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(),
+			"ginjects: bound ref field %s in %s to %s",
+			fieldName,
+			o,
+			existing,
+		)
+	}
+	return nil
+}
+
+// Replace 替换容器（不包括父容器）中命名的实例，需要在 Init 之后调用，用于配置的热加载
+// 新的实例不会进行依赖注入，Replace 依次完成新的实例的初始化、Ref 字段切换到新的实例、销毁原来的实例
+// 只有 Ref、Provider 字段以及之后的 Obtain 会获取到新的实例（装饰器重新装饰新的实例），可以与 Obtain、Inspect 等并发调用
+// 注意：普通字段（以及 Lazy 字段已经获取的实例）不会更新，仍然是原来的、已经销毁的实例，需要热加载的实例只应该通过 Ref 或 Provider 字段注入
+// @param name string "命名"
+// @param value interface "新的实例，类型需要与原来的实例相同"
+// @return error 新的实例初始化失败时不会替换；原来的实例销毁失败时已经替换，返回 *ObjectError
+func (c *Container) Replace(name string, value interface{}) error {
+	return c.ReplaceContext(context.Background(), name, value)
+}
+
+// ReplaceContext 同 Replace，ctx 传递给实例的 InitContext、CloseContext 方法
+func (c *Container) ReplaceContext(ctx context.Context, name string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	existing := c.g.named[name]
+	if existing == nil {
+		return fmt.Errorf("%w named `%s`", ErrNotFound, name)
+	}
+	if !c.initialized(existing) {
+		return fmt.Errorf("ginjects: replace object named %s before it is initialized", name)
+	}
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		return fmt.Errorf("ginjects: replaced object named %s must not be nil", name)
+	}
+	// The same type keeps the object assignable to all of its dependents.
+	if typ != existing.reflectType {
+		return fmt.Errorf(
+			"ginjects: replaced object named %s of type %s must be of its original type %s",
+			name, typ, existing.reflectType,
+		)
+	}
+
+	old := *existing
+	replaced := &object{value: value, name: name, reflectType: typ, reflectValue: reflect.ValueOf(value)}
+	replaced.initFn, replaced.closeFn = lifecycleFns(value)
+	if replaced.initFn != nil {
+		start := time.Now()
+		err := replaced.initFn(ctx)
+		c.record("init", replaced, start, time.Since(start), err)
+		if err != nil {
+			return &ObjectError{Object: replaced.String(), Op: "init", Err: err}
+		}
+	}
+	if existing.ref != nil {
+		existing.ref.store(value)
+	}
+	valueMu.Lock()
+	existing.value, existing.reflectType, existing.reflectValue = value, replaced.reflectType, replaced.reflectValue
+	existing.initFn, existing.closeFn = replaced.initFn, replaced.closeFn
+	existing.version++
+	valueMu.Unlock()
+	// The graphs of the child containers check the version.
+	c.g.forgetDecorated(existing)
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(), "ginjects: replaced %s by %s", &old, existing)
+	}
+
+	if old.closeFn == nil {
+		return nil
+	}
+	start := time.Now()
+	err := closeObject(ctx, &old)
+	c.record("close", &old, start, time.Since(start), err)
+	if err != nil {
+		return &ObjectError{Object: old.String(), Op: "close", Err: err}
+	}
+	return nil
+}
+
+// initialized reports whether the object was initialized and not yet closed.
+func (c *Container) initialized(o *object) bool {
+	for _, obj := range c.inited {
+		if obj == o {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace 替换默认容器中命名的实例
+func Replace(name string, value interface{}) error {
+	return defaultContainer.Replace(name, value)
+}
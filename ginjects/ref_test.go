@@ -0,0 +1,171 @@
+package ginjects
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForRefFlags interface {
+	Enabled(flag string) bool
+}
+
+type TypeForRefFlagClient struct {
+	enabled  map[string]bool
+	inits    int
+	closes   int
+	closeErr error
+}
+
+func (f *TypeForRefFlagClient) Enabled(flag string) bool {
+	return f.enabled[flag]
+}
+
+func (f *TypeForRefFlagClient) InitContext(ctx context.Context) error {
+	f.inits++
+	return nil
+}
+
+func (f *TypeForRefFlagClient) CloseContext(ctx context.Context) error {
+	f.closes++
+	return f.closeErr
+}
+
+type TypeForRefService struct {
+	Flags     Ref[TypeForRefFlags]        `inject:"flags"`
+	RateLimit Ref[int]                    `inject:"rate_limit"`
+	Client    *TypeForRefFlagClient       `inject:"flags"`
+	Lazy      Lazy[*TypeForRefFlagClient] `inject:"flags"`
+}
+
+func TestRefReplace(t *testing.T) {
+	c := NewContainer()
+	old := &TypeForRefFlagClient{enabled: map[string]bool{"new_ui": false}}
+	c.ProvideByName("flags", old)
+	c.ProvideByName("rate_limit", 100)
+	var v TypeForRefService
+	c.ProvideByValue(&v)
+	ensure.Nil(t, c.Init())
+	ensure.False(t, v.Flags.Get().Enabled("new_ui"))
+	ensure.DeepEqual(t, v.RateLimit.Get(), 100)
+
+	replaced := &TypeForRefFlagClient{enabled: map[string]bool{"new_ui": true}}
+	ensure.Nil(t, c.Replace("flags", replaced))
+	ensure.Nil(t, c.Replace("rate_limit", 200))
+	ensure.True(t, v.Flags.Get().Enabled("new_ui"))
+	ensure.DeepEqual(t, v.RateLimit.Get(), 200)
+	ensure.DeepEqual(t, replaced.inits, 1)
+	ensure.DeepEqual(t, old.closes, 1)
+	// Plain fields keep the old value, the obtained value is the new one.
+	ensure.True(t, v.Client == old)
+	ensure.True(t, v.Lazy.MustGet() == replaced)
+	named, err := ObtainNamedFrom[*TypeForRefFlagClient](c, "flags")
+	ensure.Nil(t, err)
+	ensure.True(t, named == replaced)
+
+	c.Close()
+	ensure.DeepEqual(t, replaced.closes, 1)
+	ensure.DeepEqual(t, old.closes, 1)
+}
+
+func TestRefReplaceErrors(t *testing.T) {
+	c := NewContainer()
+	old := &TypeForRefFlagClient{closeErr: errors.New("broken pipe")}
+	c.ProvideByName("flags", old)
+	c.ProvideByName("rate_limit", 100)
+	ensure.NotNil(t, c.Replace("flags", &TypeForRefFlagClient{}))
+	var v TypeForRefService
+	c.ProvideByValue(&v)
+	ensure.Nil(t, c.Init())
+
+	ensure.True(t, errors.Is(c.Replace("missing", 1), ErrNotFound))
+	ensure.NotNil(t, c.Replace("rate_limit", "fast"))
+	// Assignable to the Ref field but not of the original type.
+	ensure.NotNil(t, c.Replace("flags", &TypeForRefFlagsDecorated{}))
+	ensure.NotNil(t, c.Replace("flags", nil))
+	ensure.DeepEqual(t, v.RateLimit.Get(), 100)
+
+	replaced := &TypeForRefFlagClient{}
+	err := c.Replace("flags", replaced)
+	var objErr *ObjectError
+	ensure.True(t, errors.As(err, &objErr))
+	ensure.DeepEqual(t, objErr.Op, "close")
+	ensure.True(t, v.Flags.Get() == replaced)
+}
+
+func TestRefUnnamed(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForRefFlagClient{})
+	var v struct {
+		Flags Ref[*TypeForRefFlagClient] `inject:""`
+	}
+	c.ProvideByValue(&v)
+	err := c.Init()
+	ensure.NotNil(t, err)
+	ensure.StringContains(t, err.Error(), "ref field Flags in type")
+}
+
+type TypeForRefFlagsDecorated struct {
+	TypeForRefFlags
+}
+
+func TestRefReplaceDecorated(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByName("flags", TypeForRefFlags(&TypeForRefFlagClient{enabled: map[string]bool{"new_ui": false}}))
+	c.ProvideDecorator(func(flags TypeForRefFlags) TypeForRefFlags {
+		return &TypeForRefFlagsDecorated{TypeForRefFlags: flags}
+	})
+	var v struct {
+		Flags Provider[TypeForRefFlags] `inject:"flags"`
+	}
+	c.ProvideByValue(&v)
+	ensure.Nil(t, c.Init())
+	ensure.False(t, v.Flags.MustGet().Enabled("new_ui"))
+
+	ensure.Nil(t, c.Replace("flags", &TypeForRefFlagClient{enabled: map[string]bool{"new_ui": true}}))
+	flags := v.Flags.MustGet()
+	ensure.True(t, flags.Enabled("new_ui"))
+	_, decorated := flags.(*TypeForRefFlagsDecorated)
+	ensure.True(t, decorated)
+	c.Close()
+}
+
+func TestRefReplaceConcurrent(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByName("flags", &TypeForRefFlagClient{})
+	var v TypeForRefService
+	c.ProvideByValue(&v)
+	c.ProvideByName("rate_limit", 100)
+	ensure.Nil(t, c.Init())
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				_, err := ObtainNamedFrom[*TypeForRefFlagClient](c, "flags")
+				ensure.Nil(t, err)
+				_ = c.ObtainByName("flags")
+				_ = v.Lazy.MustGet()
+				_ = c.Inspect()
+				_ = c.Health(context.Background())
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		ensure.Nil(t, c.Replace("flags", &TypeForRefFlagClient{}))
+	}
+	close(done)
+	wg.Wait()
+	c.Close()
+}
@@ -0,0 +1,118 @@
+package ginjects
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/erkesi/gobean/glogs"
+	"github.com/erkesi/gobean/grecovers"
+)
+
+// LifecycleEvent 实例的一次初始化或销毁
+type LifecycleEvent struct {
+	Object   string        `json:"object"` // 实例的描述，如："*pkg.DB named db"
+	Op       string        `json:"op"`     // 生命周期方法：init、close
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"` // 失败时的错误
+}
+
+// LifecycleReport 容器（不包括父容器）的实例的初始化与销毁的记录，只包括实现了生命周期接口的实例
+type LifecycleReport struct {
+	InitDuration time.Duration     `json:"init_duration"` // 完成全部实例初始化的耗时（不包括依赖注入）
+	Events       []*LifecycleEvent `json:"events"`        // 按照完成的顺序，包括 Replace 时的初始化与销毁
+}
+
+// Report 返回实例的初始化与销毁的记录
+func (c *Container) Report() *LifecycleReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &LifecycleReport{
+		InitDuration: c.initDuration,
+		Events:       append([]*LifecycleEvent{}, c.events...),
+	}
+}
+
+// Report 返回默认容器的实例的初始化与销毁的记录
+func Report() *LifecycleReport {
+	return defaultContainer.Report()
+}
+
+// record adds the lifecycle event of the object to the report, c.mu must be
+// held.
+func (c *Container) record(op string, obj *object, start time.Time, cost time.Duration, err error) {
+	event := &LifecycleEvent{Object: obj.String(), Op: op, Start: start, Duration: cost}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	c.events = append(c.events, event)
+	if glogs.Log != nil {
+		glogs.Log.Debugf(context.TODO(), "ginjects: %s inject object(%v) cost %s", op, obj, cost)
+	}
+}
+
+// HealthChecker 实例的健康检查，Container.Health 汇总全部已经初始化的实例的状态
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthReport 容器的健康状态
+type HealthReport struct {
+	Ready      bool               `json:"ready"`           // Init 成功、没有 Close 并且全部实例的健康检查通过
+	Error      string             `json:"error,omitempty"` // 容器没有完成初始化的原因
+	Components []*ComponentHealth `json:"components,omitempty"`
+}
+
+// ComponentHealth 实现了 HealthChecker 的实例的健康状态
+type ComponentHealth struct {
+	Object   string        `json:"object"`
+	Healthy  bool          `json:"healthy"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+var errNotReady = errors.New("ginjects: container is not initialized")
+
+// Health 依次调用容器（不包括父容器）中已经初始化的实例的 HealthCheck，汇总容器的健康状态，用于 readiness 检查
+// HealthCheck 返回错误或者 panic 时，实例不健康
+func (c *Container) Health(ctx context.Context) *HealthReport {
+	c.mu.Lock()
+	ready, initErr := c.ready, c.initErr
+	inited := append([]*object{}, c.inited...)
+	c.mu.Unlock()
+
+	report := &HealthReport{Ready: ready}
+	if !ready {
+		if initErr == nil {
+			initErr = errNotReady
+		}
+		report.Error = initErr.Error()
+		return report
+	}
+	for _, obj := range inited {
+		valueMu.RLock()
+		value, name := obj.value, obj.String()
+		valueMu.RUnlock()
+		checker, ok := value.(HealthChecker)
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		err := grecovers.RecoverOfErr(func() error {
+			return checker.HealthCheck(ctx)
+		})
+		component := &ComponentHealth{Object: name, Healthy: err == nil, Duration: time.Since(start)}
+		if err != nil {
+			component.Error = err.Error()
+			report.Ready = false
+		}
+		report.Components = append(report.Components, component)
+	}
+	return report
+}
+
+// Health 返回默认容器的健康状态
+func Health(ctx context.Context) *HealthReport {
+	return defaultContainer.Health(ctx)
+}
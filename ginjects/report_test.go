@@ -0,0 +1,101 @@
+package ginjects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForHealthDB struct {
+	err error
+}
+
+func (d *TypeForHealthDB) HealthCheck(ctx context.Context) error {
+	return d.err
+}
+
+type TypeForHealthCache struct {
+	DB *TypeForHealthDB `inject:""`
+}
+
+func (c *TypeForHealthCache) Init() {
+}
+
+func (c *TypeForHealthCache) HealthCheck(ctx context.Context) error {
+	panic("not connected")
+}
+
+func TestReport(t *testing.T) {
+	c := NewContainer()
+	recorder := &TypeForLifecycleRecorder{}
+	c.ProvideByValue(recorder)
+	c.ProvideByValue(&TypeForLifecycleDB{closeErr: errors.New("broken pipe")})
+	c.ProvideByValue(&TypeForLifecycleCache{})
+	ensure.Nil(t, c.Init())
+	c.Close()
+
+	report := c.Report()
+	ensure.True(t, report.InitDuration > 0)
+	var events []string
+	for _, event := range report.Events {
+		events = append(events, event.Op+" "+event.Object+" "+event.Error)
+	}
+	ensure.DeepEqual(t, events, []string{
+		`init "*ginjects.TypeForLifecycleDB" `,
+		`init "*ginjects.TypeForLifecycleCache" `,
+		`close "*ginjects.TypeForLifecycleCache" `,
+		`close "*ginjects.TypeForLifecycleDB" broken pipe`,
+	})
+}
+
+func TestReportInitFailed(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForLifecycleRecorder{})
+	c.ProvideByValue(&TypeForLifecycleRepo{})
+	ensure.NotNil(t, c.Init(WithInitParallel(0)))
+
+	report := c.Report()
+	ensure.DeepEqual(t, len(report.Events), 5)
+	ensure.DeepEqual(t, report.Events[2].Object, `"*ginjects.TypeForLifecycleRepo"`)
+	ensure.DeepEqual(t, report.Events[2].Error, "connection refused")
+
+	health := c.Health(context.Background())
+	ensure.False(t, health.Ready)
+	ensure.StringContains(t, health.Error, "connection refused")
+}
+
+func TestHealth(t *testing.T) {
+	c := NewContainer()
+	db := &TypeForHealthDB{}
+	c.ProvideByValue(db)
+	ensure.False(t, c.Health(context.Background()).Ready)
+	ensure.Nil(t, c.Init())
+
+	health := c.Health(context.Background())
+	ensure.True(t, health.Ready)
+	ensure.DeepEqual(t, len(health.Components), 1)
+	ensure.True(t, health.Components[0].Healthy)
+
+	db.err = errors.New("connection refused")
+	health = c.Health(context.Background())
+	ensure.False(t, health.Ready)
+	ensure.DeepEqual(t, health.Components[0].Error, "connection refused")
+
+	c.Close()
+	ensure.False(t, c.Health(context.Background()).Ready)
+}
+
+func TestHealthPanic(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForHealthCache{})
+	ensure.Nil(t, c.Init())
+
+	health := c.Health(context.Background())
+	ensure.False(t, health.Ready)
+	ensure.DeepEqual(t, len(health.Components), 2)
+	ensure.True(t, health.Components[0].Healthy)
+	ensure.False(t, health.Components[1].Healthy)
+	ensure.StringContains(t, health.Components[1].Error, "not connected")
+}
@@ -0,0 +1,103 @@
+package ginjects
+
+import (
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithProvideSetter 指定未导出字段的 setter 方法，默认为 Set 加上首字母大写的字段名，如：字段 db 的 setter 为 SetDb
+// setter 方法的签名为 func(dep T) 或者 func(dep T) error，实例的依赖可以不导出
+// @param field string "未导出的字段名，如：db"
+// @param method string "方法名，如：UseDB"
+func WithProvideSetter(field, method string) ProvideOption {
+	return func(opt *provideOptions) {
+		if opt.setters == nil {
+			opt.setters = make(map[string]string)
+		}
+		opt.setters[field] = method
+	}
+}
+
+// settableField returns the field to populate. An unexported field is
+// populated through a temporary value, apply passes it to the setter of the
+// field once populated.
+func (g *graph) settableField(o *object, i int, t *tag) (field reflect.Value, apply func() error, err error) {
+	field = o.reflectValue.Elem().Field(i)
+	if field.CanSet() {
+		return field, nil, nil
+	}
+	structField := o.reflectType.Elem().Field(i)
+	setter, err := o.setter(structField)
+	if err != nil {
+		return field, nil, err
+	}
+	if t.Inline {
+		return field, nil, fmt.Errorf(
+			"ginjects: inline requested on unexported field %s in type %s",
+			structField.Name,
+			o.reflectType,
+		)
+	}
+
+	// Don't overwrite existing values, the field is left as is.
+	if !isNilOrZero(field, field.Type()) {
+		return field, nil, nil
+	}
+
+	value := reflect.New(field.Type()).Elem()
+	return value, func() error {
+		if isNilOrZero(value, value.Type()) {
+			return nil
+		}
+		out := setter.Call([]reflect.Value{value})
+		if len(out) == 1 && !out[0].IsNil() {
+			return fmt.Errorf(
+				"ginjects: setter of field %s in type %s failed: %w",
+				structField.Name,
+				o.reflectType,
+				out[0].Interface().(error),
+			)
+		}
+		return nil
+	}, nil
+}
+
+// setter returns the method setting the unexported field.
+func (o *object) setter(field reflect.StructField) (reflect.Value, error) {
+	name, ok := o.setters[field.Name]
+	if !ok {
+		r, size := utf8.DecodeRuneInString(field.Name)
+		name = "Set" + string(unicode.ToUpper(r)) + field.Name[size:]
+	}
+	method := o.reflectValue.MethodByName(name)
+	if !method.IsValid() {
+		if ok {
+			return method, fmt.Errorf(
+				"ginjects: did not find setter %s of field %s in type %s",
+				name,
+				field.Name,
+				o.reflectType,
+			)
+		}
+		return method, fmt.Errorf(
+			"ginjects: inject requested on unexported field %s in type %s",
+			field.Name,
+			o.reflectType,
+		)
+	}
+	typ := method.Type()
+	if typ.NumIn() != 1 || !field.Type.AssignableTo(typ.In(0)) ||
+		typ.NumOut() > 1 || (typ.NumOut() == 1 && typ.Out(0) != errorType) {
+		return method, fmt.Errorf(
+			"ginjects: setter %s of field %s in type %s must be of type func(%s) or func(%s) error",
+			name,
+			field.Name,
+			o.reflectType,
+			field.Type,
+			field.Type,
+		)
+	}
+	return method, nil
+}
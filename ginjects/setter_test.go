@@ -0,0 +1,87 @@
+package ginjects
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForSetterDB struct{}
+
+type TypeForSetterRepo struct {
+	db       *TypeForSetterDB           `inject:""`
+	answer   Answerable                 `inject:""`
+	named    *TypeForSetterDB           `inject:"db"`
+	handlers []TypeForCollectionHandler `inject:""`
+}
+
+func (r *TypeForSetterRepo) SetDb(db *TypeForSetterDB) {
+	r.db = db
+}
+
+func (r *TypeForSetterRepo) SetAnswer(answer Answerable) {
+	r.answer = answer
+}
+
+func (r *TypeForSetterRepo) UseNamed(db *TypeForSetterDB) error {
+	r.named = db
+	return nil
+}
+
+func (r *TypeForSetterRepo) SetHandlers(handlers []TypeForCollectionHandler) {
+	r.handlers = handlers
+}
+
+type TypeForSetterInvalid struct {
+	db *TypeForSetterDB `inject:""`
+}
+
+func (r *TypeForSetterInvalid) SetDb(db string) {
+}
+
+type TypeForSetterFailed struct {
+	db *TypeForSetterDB `inject:""`
+}
+
+func (r *TypeForSetterFailed) SetDb(db *TypeForSetterDB) error {
+	return errors.New("closed")
+}
+
+func TestSetter(t *testing.T) {
+	c := NewContainer()
+	named := &TypeForSetterDB{}
+	c.ProvideByName("db", named)
+	c.ProvideByValue(&TypeAnswerStruct{answer: 42})
+	c.ProvideByValue(&TypeForCollectionHandlerA{})
+	repo := &TypeForSetterRepo{}
+	c.ProvideByValue(repo, WithProvideSetter("named", "UseNamed"))
+	ensure.Nil(t, c.Init())
+
+	ensure.NotNil(t, repo.db)
+	ensure.True(t, repo.named == named)
+	ensure.DeepEqual(t, repo.answer.Answer(), 42)
+	ensure.DeepEqual(t, len(repo.handlers), 1)
+	ensure.DeepEqual(t, repo.handlers[0].Handle(), "a")
+	ensure.DeepEqual(t, c.ExportGraph().Edges[0].Field, "answer")
+}
+
+func TestSetterErrors(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForSetterInvalid{})
+	ensure.DeepEqual(t, c.Init().Error(), "ginjects: setter SetDb of field db in type *ginjects.TypeForSetterInvalid "+
+		"must be of type func(*ginjects.TypeForSetterDB) or func(*ginjects.TypeForSetterDB) error")
+
+	c = NewContainer()
+	c.ProvideByValue(&TypeForSetterFailed{})
+	ensure.DeepEqual(t, c.Init().Error(), "ginjects: setter of field db in type *ginjects.TypeForSetterFailed failed: closed")
+
+	c = NewContainer()
+	c.ProvideByValue(&TypeForSetterRepo{}, WithProvideSetter("named", "UseDB"))
+	ensure.DeepEqual(t, c.Init().Error(), "ginjects: did not find setter UseDB of field named in type *ginjects.TypeForSetterRepo")
+
+	c = NewContainer()
+	c.ProvideByValue(&TypeAnswerStruct{answer: 42})
+	c.ProvideByValue(&TypeForSetterRepo{}, WithProvideSetter("answer", "UseAnswer"))
+	ensure.DeepEqual(t, c.Init().Error(), "ginjects: did not find setter UseAnswer of field answer in type *ginjects.TypeForSetterRepo")
+}
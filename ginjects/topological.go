@@ -3,6 +3,7 @@ package ginjects
 import (
 	"fmt"
 	"sort"
+	"strings"
 )
 
 type EdgeNode struct {
@@ -21,6 +22,22 @@ func (e Edge) String() string {
 	return fmt.Sprintf("%d-%d", e[0], e[1])
 }
 
+// CycleError 实例之间存在循环依赖
+type CycleError struct {
+	// Cycles 每个循环依赖的链路，如：[*pkg.A.B *pkg.B.C *pkg.C.A]
+	// 表示 A 的字段 B 依赖 B，B 的字段 C 依赖 C，C 的字段 A 依赖 A
+	Cycles [][]string
+	nodes  [][]EdgeNode // The cycles in dependency order
+}
+
+func (e *CycleError) Error() string {
+	chains := make([]string, 0, len(e.Cycles))
+	for _, cycle := range e.Cycles {
+		chains = append(chains, strings.Join(cycle, " -> "))
+	}
+	return fmt.Sprintf("ginjects: graph contains cycle %s", strings.Join(chains, "; "))
+}
+
 // Toposort performs a topological sort of the DAG defined by given edges.
 //
 // Takes a slice of Edge, where each element is a vertex pair representing an
@@ -31,7 +48,9 @@ func (e Edge) String() string {
 // node with one nil vertex.  It can appear anywhere in the sorted output.
 //
 // Returns an ordered list of vertexes where each vertex occurs before any of
-// its destination vertexes.  An error is returned if a cycle is detected.
+// its destination vertexes.  A *CycleError is returned if a cycle is detected,
+// holding one cycle for each strongly connected component of the vertexes left
+// unsorted.
 func Toposort(edges []Edge, allNodes []EdgeNode) ([]EdgeNode, []EdgeNode, error) {
 	g, sortNodes, err := makeGraph(edges, allNodes)
 	if err != nil {
@@ -90,7 +109,15 @@ func Toposort(edges []Edge, allNodes []EdgeNode) ([]EdgeNode, []EdgeNode, error)
 			}
 		}
 		sort.Slice(cycleNodes, sortEdgeNodes(cycleNodes))
-		return nil, cycleNodes, fmt.Errorf("ginjects: graph contains cycle in nodes %s", cycleNodes)
+		cycleErr := &CycleError{nodes: findCycles(g, cycleNodes)}
+		for _, cycle := range cycleErr.nodes {
+			chain := make([]string, 0, len(cycle))
+			for _, n := range cycle {
+				chain = append(chain, n.String())
+			}
+			cycleErr.Cycles = append(cycleErr.Cycles, chain)
+		}
+		return nil, cycleNodes, cycleErr
 	}
 
 	// Return the sorted vertex list
@@ -132,3 +159,126 @@ func sortEdgeNodes(nodes []EdgeNode) func(i int, j int) bool {
 		return nodes[i].priority > nodes[j].priority
 	}
 }
+
+// findCycles returns a cycle of each strongly connected component of the
+// nodes, in dependency order: every node depends on the next one and the last
+// one depends on the first one.
+func findCycles(g map[EdgeNode][]EdgeNode, nodes []EdgeNode) [][]EdgeNode {
+	inNodes := make(map[EdgeNode]bool, len(nodes))
+	for _, n := range nodes {
+		inNodes[n] = true
+	}
+	// The edges of g go from a dependency to its dependent, reverse them.
+	deps := make(map[EdgeNode][]EdgeNode, len(nodes))
+	for _, u := range nodes {
+		for _, v := range g[u] {
+			if inNodes[v] {
+				deps[v] = append(deps[v], u)
+			}
+		}
+	}
+	for _, ns := range deps {
+		sort.Slice(ns, sortEdgeNodes(ns))
+	}
+
+	var cycles [][]EdgeNode
+	for _, component := range stronglyConnected(nodes, deps) {
+		if cycle := shortestCycle(component[0], deps, component); cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+// stronglyConnected returns the strongly connected components using Tarjan's
+// algorithm, each component is sorted and the components are ordered by their
+// first node.
+func stronglyConnected(nodes []EdgeNode, adjacent map[EdgeNode][]EdgeNode) [][]EdgeNode {
+	index := 0
+	indexes := make(map[EdgeNode]int, len(nodes))
+	lowLinks := make(map[EdgeNode]int, len(nodes))
+	onStack := make(map[EdgeNode]bool, len(nodes))
+	var stack []EdgeNode
+	var components [][]EdgeNode
+
+	var connect func(u EdgeNode)
+	connect = func(u EdgeNode) {
+		indexes[u], lowLinks[u] = index, index
+		index++
+		stack = append(stack, u)
+		onStack[u] = true
+		for _, v := range adjacent[u] {
+			if _, visited := indexes[v]; !visited {
+				connect(v)
+				if lowLinks[v] < lowLinks[u] {
+					lowLinks[u] = lowLinks[v]
+				}
+			} else if onStack[v] && indexes[v] < lowLinks[u] {
+				lowLinks[u] = indexes[v]
+			}
+		}
+		if lowLinks[u] != indexes[u] {
+			return
+		}
+		var component []EdgeNode
+		for {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[v] = false
+			component = append(component, v)
+			if v == u {
+				break
+			}
+		}
+		sort.Slice(component, sortEdgeNodes(component))
+		components = append(components, component)
+	}
+	for _, n := range nodes {
+		if _, visited := indexes[n]; !visited {
+			connect(n)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return sortEdgeNodes([]EdgeNode{components[i][0], components[j][0]})(0, 1)
+	})
+	return components
+}
+
+// shortestCycle returns the shortest cycle starting from the node within the
+// component, or nil if there is none.
+func shortestCycle(start EdgeNode, adjacent map[EdgeNode][]EdgeNode, component []EdgeNode) []EdgeNode {
+	inComponent := make(map[EdgeNode]bool, len(component))
+	for _, n := range component {
+		inComponent[n] = true
+	}
+	prev := make(map[EdgeNode]EdgeNode)
+	visited := map[EdgeNode]bool{start: true}
+	queue := []EdgeNode{start}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range adjacent[u] {
+			if !inComponent[v] {
+				continue
+			}
+			if v == start {
+				cycle := []EdgeNode{u}
+				for n := u; n != start; {
+					n = prev[n]
+					cycle = append(cycle, n)
+				}
+				// Reverse the path walked back from u to start.
+				for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+				return cycle
+			}
+			if !visited[v] {
+				visited[v] = true
+				prev[v] = u
+				queue = append(queue, v)
+			}
+		}
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package ginjects
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	"github.com/facebookgo/ensure"
 )
 
 func TestToposort(t *testing.T) {
@@ -96,3 +99,40 @@ func TestToposort(t *testing.T) {
 		})
 	}
 }
+
+func TestToposortCycleError(t *testing.T) {
+	a, b, c, d := EdgeNode{index: 1}, EdgeNode{index: 2}, EdgeNode{index: 3}, EdgeNode{index: 4}
+	// a depends on b, b on c, c on a and d on a.
+	_, _, err := Toposort([]Edge{{b, a}, {c, b}, {a, c}, {a, d}, {d, d}}, nil)
+	var cycleErr *CycleError
+	ensure.True(t, errors.As(err, &cycleErr))
+	ensure.DeepEqual(t, cycleErr.Cycles, [][]string{{"1(0)", "2(0)", "3(0)"}, {"4(0)"}})
+	ensure.DeepEqual(t, err.Error(), "ginjects: graph contains cycle 1(0) -> 2(0) -> 3(0); 4(0)")
+}
+
+type TypeForCycleA struct {
+	B *TypeForCycleB `inject:""`
+}
+
+type TypeForCycleB struct {
+	C *TypeForCycleC `inject:""`
+}
+
+type TypeForCycleC struct {
+	A *TypeForCycleA `inject:""`
+}
+
+type TypeForCycleD struct {
+	A *TypeForCycleA `inject:""`
+}
+
+func TestNamedCycleError(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForCycleA{})
+	c.ProvideByValue(&TypeForCycleD{})
+	err := c.Init()
+	var cycleErr *CycleError
+	ensure.True(t, errors.As(err, &cycleErr))
+	ensure.DeepEqual(t, cycleErr.Cycles, [][]string{{"*ginjects.TypeForCycleA.B", "*ginjects.TypeForCycleB.C", "*ginjects.TypeForCycleC.A"}})
+	ensure.DeepEqual(t, err.Error(), "ginjects: graph contains cycle *ginjects.TypeForCycleA.B -> *ginjects.TypeForCycleB.C -> *ginjects.TypeForCycleC.A")
+}
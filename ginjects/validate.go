@@ -0,0 +1,309 @@
+package ginjects
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationProblem 依赖注入的问题
+type ValidationProblem struct {
+	Path string // 字段路径，如：*main.App.Service.DB，由 ginjects 创建的实例从注入它的字段开始，依赖的循环为空
+	Err  error
+}
+
+func (p *ValidationProblem) Error() string {
+	if p.Path == "" {
+		return p.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+func (p *ValidationProblem) Unwrap() error {
+	return p.Err
+}
+
+// ValidationReport 依赖注入的校验结果
+type ValidationReport struct {
+	Problems []*ValidationProblem // 依赖注入的问题，包括依赖的循环
+	Unused   []string             // 没有被其他实例依赖的实例，只通过 Obtain 获取的实例也会出现在这里
+}
+
+// Err 存在依赖注入的问题时返回 Errors，否则返回 nil
+func (r *ValidationReport) Err() error {
+	if len(r.Problems) == 0 {
+		return nil
+	}
+	errs := make(Errors, 0, len(r.Problems))
+	for _, p := range r.Problems {
+		errs = append(errs, p)
+	}
+	return errs
+}
+
+// String 每行一个问题，之后是没有被依赖的实例
+func (r *ValidationReport) String() string {
+	var builder strings.Builder
+	for _, p := range r.Problems {
+		builder.WriteString(fmt.Sprintf("problem: %v\n", p))
+	}
+	for _, unused := range r.Unused {
+		builder.WriteString(fmt.Sprintf("unused: %s\n", unused))
+	}
+	return builder.String()
+}
+
+// Validate 校验依赖注入（包括父容器），不会调用构造函数以及实例的初始化方法，也不会修改注入的实例，需要在 Init 之前调用
+// 构造函数的返回值使用零值代替，标签为 `inject:"private"` 的延迟注入字段不会校验
+// @return *ValidationReport 所有依赖注入的问题（字段路径）、依赖的循环以及没有被依赖的实例
+func (c *Container) Validate() *ValidationReport {
+	return c.g.validate()
+}
+
+// Validate 校验默认容器的依赖注入
+func Validate() *ValidationReport {
+	return defaultContainer.Validate()
+}
+
+// A problem found when the graph is populated in dry run mode.
+type problem struct {
+	object *object // Optional, the object whose field failed
+	owner  string  // Optional, used when object is nil, e.g. the constructor type
+	field  string  // Optional
+	err    error
+}
+
+// A lazy field resolved after the graph is populated in dry run mode.
+type lazyCheck struct {
+	object  *object
+	field   string
+	resolve func() (interface{}, error)
+}
+
+// report returns the error, or records it and returns nil in dry run mode.
+func (g *graph) report(o *object, field string, err error) error {
+	return g.reportProblem(&problem{object: o, field: field, err: err})
+}
+
+func (g *graph) reportProblem(p *problem) error {
+	if !g.dryRun {
+		return p.err
+	}
+	// Both passes may report the same malformed tag.
+	for _, existing := range g.problems {
+		if existing.object == p.object && existing.owner == p.owner && existing.field == p.field &&
+			existing.err.Error() == p.err.Error() {
+			return nil
+		}
+	}
+	g.problems = append(g.problems, p)
+	return nil
+}
+
+// clone copies the graph and its parents. The provided struct values are
+// copied so populating the clone leaves them untouched, the created, embedded
+// and constructed objects are left out to be populated again.
+func (g *graph) clone() *graph {
+	c := newGraph(nil)
+	if g.parent != nil {
+		c.parent = g.parent.clone()
+	}
+	c.sources = g.sources
+	indexes := make([]int, 0, len(g.index2Object))
+	for index := range g.index2Object {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		o := g.index2Object[index]
+		if o.created || o.embedded || o.args != nil {
+			continue
+		}
+		// The objects were valid when provided to the original graph.
+		_ = c.provide(copyObject(o))
+	}
+	for _, ctor := range g.constructors {
+		c.constructors = append(c.constructors, &constructor{fn: ctor.fn, opt: ctor.opt})
+	}
+	for _, cond := range g.conditionals {
+		if cond.resolved {
+			continue
+		}
+		copied := &conditional{conditions: cond.conditions}
+		if cond.constructor != nil {
+			copied.constructor = &constructor{fn: cond.constructor.fn, opt: cond.constructor.opt}
+		} else {
+			copied.object = copyObject(cond.object)
+		}
+		c.conditionals = append(c.conditionals, copied)
+	}
+	c.profiles = g.profiles
+	c.decorators = g.decorators
+	return c
+}
+
+// copyObject returns an object yet to be provided holding a copy of the value
+// of o, struct values are shallow-copied.
+func copyObject(o *object) *object {
+	value := o.value
+	if v := reflect.ValueOf(value); v.IsValid() && isStructPtr(v.Type()) && !v.IsNil() {
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		value = copied.Interface()
+	}
+	return &object{
+		value:        value,
+		name:         o.name,
+		priority:     o.priority,
+		complete:     o.complete,
+		private:      o.private,
+		primary:      o.primary,
+		qualifiers:   o.qualifiers,
+		setters:      o.setters,
+		closeTimeout: o.closeTimeout,
+	}
+}
+
+// providePlaceholders provides zero values for the results of the constructor
+// in dry run mode. The results of interface types have no concrete type, they
+// are complete objects of the interface type holding nil.
+func (g *graph) providePlaceholders(c *constructor, deps []*object) error {
+	for _, result := range c.results() {
+		var o *object
+		if isStructPtr(result) {
+			o = c.opt.object(reflect.New(result.Elem()).Interface(), "")
+			if err := g.provide(o); err != nil {
+				return err
+			}
+		} else {
+			o = c.opt.object(nil, "")
+			g.index++
+			o.index = g.index
+			o.complete = true
+			o.reflectType = result
+			o.reflectValue = reflect.Zero(result)
+			g.allNodes = append(g.allNodes, EdgeNode{index: o.index, priority: o.priority})
+			if g.index2Object == nil {
+				g.index2Object = make(map[int]*object)
+			}
+			g.index2Object[o.index] = o
+			g.unnamed = append(g.unnamed, o)
+		}
+		o.args = deps
+		o.constructor = c
+		for _, dep := range deps {
+			o.addEdge(g, dep)
+		}
+	}
+	return nil
+}
+
+func (g *graph) validate() *ValidationReport {
+	var chain []*graph
+	for cur := g.clone(); cur != nil; cur = cur.parent {
+		cur.dryRun = true
+		chain = append([]*graph{cur}, chain...)
+	}
+	report := &ValidationReport{}
+	used := make(map[*object]bool)
+	for _, cur := range chain {
+		if err := cur.populate(); err != nil {
+			cur.problems = append(cur.problems, &problem{err: err})
+		}
+		for _, check := range cur.lazyChecks {
+			value, err := check.resolve()
+			if err != nil {
+				_ = cur.report(check.object, check.field, err)
+				continue
+			}
+			if dep := cur.objectOf(value); dep != nil {
+				used[dep] = true
+			}
+		}
+		for _, p := range cur.problems {
+			report.Problems = append(report.Problems, &ValidationProblem{Path: cur.problemPath(p), Err: p.err})
+		}
+	}
+
+	leaf := chain[len(chain)-1]
+	for _, o := range leaf.index2Object {
+		for _, dep := range o.fields {
+			used[dep] = true
+		}
+		for _, dep := range o.args {
+			used[dep] = true
+		}
+	}
+	var unused []*object
+	for _, o := range leaf.index2Object {
+		if !used[o] && !o.created && !o.embedded && !o.private {
+			unused = append(unused, o)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].index < unused[j].index
+	})
+	for _, o := range unused {
+		report.Unused = append(report.Unused, o.String())
+	}
+	return report
+}
+
+// objectOf returns the object holding the pointer value, searching the parent
+// graphs as well.
+func (g *graph) objectOf(value interface{}) *object {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr {
+		return nil
+	}
+	for cur := g; cur != nil; cur = cur.parent {
+		for _, o := range cur.index2Object {
+			if o.reflectValue.Kind() == reflect.Ptr && o.reflectValue.Pointer() == v.Pointer() &&
+				o.reflectType == v.Type() {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+func (g *graph) problemPath(p *problem) string {
+	var path string
+	switch {
+	case p.object != nil:
+		path = g.objectPath(p.object, 0)
+	case p.owner != "":
+		path = p.owner
+	default:
+		return ""
+	}
+	if p.field != "" {
+		path += "." + p.field
+	}
+	return path
+}
+
+// objectPath returns the type of the object, or the path of the field it was
+// created for.
+func (g *graph) objectPath(o *object, depth int) string {
+	if o.created && depth < len(g.index2Object) {
+		var first *object
+		var firstField string
+		for _, owner := range g.index2Object {
+			for fieldName, dep := range owner.fields {
+				if dep != o {
+					continue
+				}
+				if first == nil || owner.index < first.index ||
+					(owner == first && fieldName < firstField) {
+					first, firstField = owner, fieldName
+				}
+			}
+		}
+		if first != nil {
+			return g.objectPath(first, depth+1) + "." + firstField
+		}
+	}
+	return o.label()
+}
@@ -0,0 +1,114 @@
+package ginjects
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForValidateNotifier interface {
+	Notify()
+}
+
+type TypeForValidateDB struct{}
+
+type TypeForValidateClient struct {
+	DB *TypeForValidateDB `inject:""`
+}
+
+type TypeForValidateRepo struct {
+	DB       *TypeForValidateDB      `inject:""`
+	Cache    Answerable              `inject:"cache"`
+	Notifier TypeForValidateNotifier `inject:""`
+}
+
+type TypeForValidateService struct {
+	Repo    *TypeForValidateRepo               `inject:""`
+	Client  *TypeForValidateClient             `inject:""`
+	Port    int                                `value:"port"`
+	Lazy    Lazy[*TypeForValidateClientHolder] `inject:""`
+	private *TypeForValidateDB                 `inject:""`
+}
+
+type TypeForValidateClientHolder struct{}
+
+type TypeForValidateApp struct {
+	Client *TypeForValidateClient `inject:""`
+}
+
+type TypeForValidateCycleA struct {
+	B *TypeForValidateCycleB `inject:""`
+}
+
+type TypeForValidateCycleB struct {
+	A *TypeForValidateCycleA `inject:""`
+}
+
+func TestValidate(t *testing.T) {
+	c := NewContainer()
+	invoked := false
+	c.ProvideByConstructor(func(db *TypeForValidateDB) (*TypeForValidateClient, error) {
+		invoked = true
+		return &TypeForValidateClient{DB: db}, nil
+	})
+	c.ProvideByConstructor(func(n TypeForValidateNotifier) Answerable {
+		invoked = true
+		return nil
+	})
+	s := &TypeForValidateService{}
+	c.ProvideByValue(s)
+	c.ProvideByValue(&TypeForValidateDB{})
+	c.ProvideByName("unused", &TypeAnswerStruct{})
+
+	report := c.Validate()
+	ensure.False(t, invoked)
+	ensure.True(t, s.Repo == nil)
+	ensure.True(t, s.Client == nil)
+
+	var problems []string
+	for _, p := range report.Problems {
+		problems = append(problems, p.Error())
+	}
+	ensure.DeepEqual(t, problems, []string{
+		"func(ginjects.TypeForValidateNotifier) ginjects.Answerable.arg0: ginjects: found no assignable value for parameter 0 (ginjects.TypeForValidateNotifier) of constructor \"func(ginjects.TypeForValidateNotifier) ginjects.Answerable\"",
+		"*ginjects.TypeForValidateService.private: ginjects: inject requested on unexported field private in type *ginjects.TypeForValidateService",
+		"*ginjects.TypeForValidateService.Repo.Cache: ginjects: did not find object named cache required by field Cache in type *ginjects.TypeForValidateRepo",
+		"*ginjects.TypeForValidateService.Port: ginjects: did not find config key port required by field Port in type *ginjects.TypeForValidateService",
+		"*ginjects.TypeForValidateService.Repo.Notifier: ginjects: found no assignable value for field Notifier in type *ginjects.TypeForValidateRepo",
+		"*ginjects.TypeForValidateService.Lazy: ginjects: found no assignable value for lazy field Lazy in type *ginjects.TypeForValidateService",
+	})
+	ensure.DeepEqual(t, report.Unused, []string{"\"*ginjects.TypeForValidateService\"", "\"*ginjects.TypeAnswerStruct named unused\"", "\"ginjects.Answerable\""})
+	ensure.DeepEqual(t, len(report.Err().(Errors)), 6)
+	ensure.True(t, strings.HasPrefix(report.String(), "problem: func(ginjects.TypeForValidateNotifier) ginjects.Answerable.arg0: "))
+	ensure.True(t, strings.HasSuffix(report.String(), "unused: \"ginjects.Answerable\"\n"))
+}
+
+func TestValidateCycle(t *testing.T) {
+	c := NewContainer()
+	c.ProvideByValue(&TypeForValidateCycleA{})
+
+	report := c.Validate()
+	ensure.DeepEqual(t, len(report.Problems), 1)
+	ensure.DeepEqual(t, report.Problems[0].Path, "")
+	ensure.DeepEqual(t, report.Problems[0].Error(), "ginjects: graph contains cycle *ginjects.TypeForValidateCycleA.B -> *ginjects.TypeForValidateCycleB.A")
+}
+
+func TestValidateThenInit(t *testing.T) {
+	parent := NewContainer()
+	parent.ProvideByValue(&TypeForValidateDB{})
+	child := parent.NewChild()
+	child.ProvideByConstructor(func(db *TypeForValidateDB) *TypeForValidateClient {
+		return &TypeForValidateClient{DB: db}
+	})
+	app := &TypeForValidateApp{}
+	child.ProvideByValue(app)
+
+	report := child.Validate()
+	ensure.Nil(t, report.Err())
+	ensure.DeepEqual(t, report.Unused, []string{"\"*ginjects.TypeForValidateApp\""})
+	ensure.True(t, app.Client == nil)
+
+	ensure.Nil(t, child.Init())
+	ensure.True(t, app.Client.DB != nil)
+}
@@ -0,0 +1,309 @@
+package ginjects
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddConfigSource 添加配置源，用于注入标签为 `value:"db.host"` 的字段，需要在 Init 之前调用
+// 多个配置源按照添加的顺序查找配置项，先添加的优先，如：flag、env、file
+// 子容器找不到配置项时从父容器的配置源查找
+func (c *Container) AddConfigSource(sources ...ConfigSource) {
+	c.g.sources = append(c.g.sources, sources...)
+}
+
+// AddConfigSource 向默认容器添加配置源
+func AddConfigSource(sources ...ConfigSource) {
+	defaultContainer.AddConfigSource(sources...)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type valueTag struct {
+	Key        string
+	Default    string
+	HasDefault bool
+}
+
+// parseValueTag parses `value:"db.host"` and `value:"db.host,default=localhost"`,
+// the default is the rest of the tag so it may contain commas.
+func parseValueTag(t string) (*valueTag, error) {
+	found, value, err := structTagExtract("value", t)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	key, option, hasOption := strings.Cut(value, ",")
+	if key == "" {
+		return nil, errInvalidTag
+	}
+	vt := &valueTag{Key: key}
+	if hasOption {
+		if !strings.HasPrefix(option, "default=") {
+			return nil, errInvalidTag
+		}
+		vt.Default, vt.HasDefault = strings.TrimPrefix(option, "default="), true
+	}
+	return vt, nil
+}
+
+// lookupValue looks the key up in the config sources, falling back to the
+// parent graph.
+func (g *graph) lookupValue(key string) (interface{}, bool) {
+	for cur := g; cur != nil; cur = cur.parent {
+		for _, source := range cur.sources {
+			if value, ok := source.Lookup(key); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// populateAllValues sets the config values of the objects in the order they
+// were provided.
+func (g *graph) populateAllValues() error {
+	objects := make([]*object, 0, len(g.index2Object))
+	for _, o := range g.index2Object {
+		if !o.complete {
+			objects = append(objects, o)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].index < objects[j].index
+	})
+	var errs Errors
+	for _, o := range objects {
+		errs = append(errs, g.populateValues(o)...)
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errs
+}
+
+// populateValues sets the fields tagged with value from the config sources,
+// every failing field is reported.
+func (g *graph) populateValues(o *object) Errors {
+	if !isStructPtr(o.reflectType) {
+		return nil
+	}
+	var errs Errors
+	for i := 0; i < o.reflectValue.Elem().NumField(); i++ {
+		if err := g.populateValue(o, i); err != nil {
+			if err := g.report(o, o.reflectType.Elem().Field(i).Name, err); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+func (g *graph) populateValue(o *object, i int) error {
+	field := o.reflectValue.Elem().Field(i)
+	structField := o.reflectType.Elem().Field(i)
+	vt, err := parseValueTag(string(structField.Tag))
+	if err != nil {
+		return fmt.Errorf(
+			"ginjects: unexpected tag format `%s` for field %s in type %s",
+			string(structField.Tag),
+			structField.Name,
+			o.reflectType,
+		)
+	}
+	if vt == nil {
+		return nil
+	}
+	if !field.CanSet() {
+		return fmt.Errorf(
+			"ginjects: value requested on unexported field %s in type %s",
+			structField.Name,
+			o.reflectType,
+		)
+	}
+
+	// Don't overwrite existing values.
+	if !isNilOrZero(field, field.Type()) {
+		return nil
+	}
+
+	raw, ok := g.lookupValue(vt.Key)
+	if !ok {
+		if !vt.HasDefault {
+			return fmt.Errorf(
+				"ginjects: did not find config key %s required by field %s in type %s",
+				vt.Key,
+				structField.Name,
+				o.reflectType,
+			)
+		}
+		raw = vt.Default
+	}
+	value, err := convertValue(raw, field.Type())
+	if err != nil {
+		return fmt.Errorf(
+			"ginjects: can not convert config key %s to field %s (%s) in type %s: %w",
+			vt.Key,
+			structField.Name,
+			field.Type(),
+			o.reflectType,
+			err,
+		)
+	}
+	field.Set(value)
+	return nil
+}
+
+// convertValue converts a raw config value, a string from env or flags or a
+// decoded value from files, to the given type.
+func convertValue(raw interface{}, typ reflect.Type) (reflect.Value, error) {
+	rv := reflect.ValueOf(raw)
+	if !rv.IsValid() {
+		return reflect.Zero(typ), nil
+	}
+	if rv.Type().AssignableTo(typ) {
+		return rv, nil
+	}
+	v := reflect.New(typ).Elem()
+
+	// Durations need a unit, a bare number like 5 would silently be 5ns.
+	if typ == durationType {
+		if rv.Kind() != reflect.String {
+			return v, fmt.Errorf("duration %v must be a string with a unit, such as 5s", raw)
+		}
+		d, err := time.ParseDuration(rv.String())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Struct:
+		default:
+			v.SetString(fmt.Sprint(raw))
+			return v, nil
+		}
+	case reflect.Bool:
+		if rv.Kind() == reflect.String {
+			b, err := strconv.ParseBool(rv.String())
+			if err != nil {
+				return v, err
+			}
+			v.SetBool(b)
+			return v, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch rv.Kind() {
+		case reflect.String:
+			i, err := strconv.ParseInt(strings.TrimSpace(rv.String()), 0, typ.Bits())
+			if err != nil {
+				return v, err
+			}
+			n = i
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = int64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			if f := rv.Float(); f != float64(int64(f)) {
+				return v, fmt.Errorf("%v is not an integer", raw)
+			}
+			n = int64(rv.Float())
+		default:
+			return v, fmt.Errorf("unsupported value %v of type %T", raw, raw)
+		}
+		if v.OverflowInt(n) {
+			return v, fmt.Errorf("%v overflows %s", raw, typ)
+		}
+		v.SetInt(n)
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch rv.Kind() {
+		case reflect.String:
+			u, err := strconv.ParseUint(strings.TrimSpace(rv.String()), 0, typ.Bits())
+			if err != nil {
+				return v, err
+			}
+			n = u
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 {
+				return v, fmt.Errorf("%v overflows %s", raw, typ)
+			}
+			n = uint64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = rv.Uint()
+		case reflect.Float32, reflect.Float64:
+			if f := rv.Float(); f < 0 || f != float64(uint64(f)) {
+				return v, fmt.Errorf("%v is not an unsigned integer", raw)
+			}
+			n = uint64(rv.Float())
+		default:
+			return v, fmt.Errorf("unsupported value %v of type %T", raw, raw)
+		}
+		if v.OverflowUint(n) {
+			return v, fmt.Errorf("%v overflows %s", raw, typ)
+		}
+		v.SetUint(n)
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		switch rv.Kind() {
+		case reflect.String:
+			f, err := strconv.ParseFloat(strings.TrimSpace(rv.String()), typ.Bits())
+			if err != nil {
+				return v, err
+			}
+			v.SetFloat(f)
+			return v, nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetFloat(float64(rv.Int()))
+			return v, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v.SetFloat(float64(rv.Uint()))
+			return v, nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(rv.Float())
+			return v, nil
+		}
+	case reflect.Slice:
+		var items []interface{}
+		switch rv.Kind() {
+		case reflect.String:
+			if rv.String() != "" {
+				for _, item := range strings.Split(rv.String(), ",") {
+					items = append(items, strings.TrimSpace(item))
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				items = append(items, rv.Index(i).Interface())
+			}
+		default:
+			return v, fmt.Errorf("unsupported value %v of type %T", raw, raw)
+		}
+		v = reflect.MakeSlice(typ, 0, len(items))
+		for i, item := range items {
+			elem, err := convertValue(item, typ.Elem())
+			if err != nil {
+				return v, fmt.Errorf("index %d: %w", i, err)
+			}
+			v = reflect.Append(v, elem)
+		}
+		return v, nil
+	}
+	return v, fmt.Errorf("unsupported value %v of type %T", raw, raw)
+}
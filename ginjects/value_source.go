@@ -0,0 +1,119 @@
+package ginjects
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ConfigSource 配置源，用于注入标签为 `value:"db.host"` 的字段
+type ConfigSource interface {
+	// Lookup 获取配置项的值
+	// @param key string "配置项，多级之间用 . 分隔，如：db.host"
+	// @return value interface "配置项的值，如：string、float64、[]interface{}"
+	// @return ok bool "是否存在配置项"
+	Lookup(key string) (value interface{}, ok bool)
+}
+
+type mapSource struct {
+	m map[string]interface{}
+}
+
+// NewMapSource 基于多级 map 的配置源，key 为 db.host 时依次查找 m["db"]["host"]，找不到时查找 m["db.host"]
+func NewMapSource(m map[string]interface{}) ConfigSource {
+	return &mapSource{m: m}
+}
+
+func (s *mapSource) Lookup(key string) (interface{}, bool) {
+	var cur interface{} = s.m
+	for _, part := range strings.Split(key, ".") {
+		switch m := cur.(type) {
+		case map[string]interface{}:
+			v, ok := m[part]
+			if !ok {
+				return s.lookupFlat(key)
+			}
+			cur = v
+		case map[interface{}]interface{}:
+			v, ok := m[part]
+			if !ok {
+				return s.lookupFlat(key)
+			}
+			cur = v
+		default:
+			return s.lookupFlat(key)
+		}
+	}
+	return cur, true
+}
+
+func (s *mapSource) lookupFlat(key string) (interface{}, bool) {
+	v, ok := s.m[key]
+	return v, ok
+}
+
+// NewFileSource 基于文件的配置源，文件内容通过 unmarshal 解析为多级 map
+// 只内置了 JSON（NewJSONFileSource），YAML、TOML 等格式需要传入对应的库的解析函数，如：gopkg.in/yaml.v3 的 yaml.Unmarshal
+// @param path string "文件路径"
+// @param unmarshal func "解析函数，如：json.Unmarshal、yaml.Unmarshal、toml.Unmarshal"
+func NewFileSource(path string, unmarshal func(data []byte, v interface{}) error) (ConfigSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ginjects: read config file %s: %w", path, err)
+	}
+	m := make(map[string]interface{})
+	if err := unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("ginjects: unmarshal config file %s: %w", path, err)
+	}
+	return NewMapSource(m), nil
+}
+
+// NewJSONFileSource 基于 JSON 文件的配置源
+func NewJSONFileSource(path string) (ConfigSource, error) {
+	return NewFileSource(path, json.Unmarshal)
+}
+
+type envSource struct {
+	prefix string
+}
+
+// NewEnvSource 基于环境变量的配置源，key 为 db.host 时查找环境变量 {PREFIX}_DB_HOST（prefix 为空时查找 DB_HOST）
+func NewEnvSource(prefix string) ConfigSource {
+	return &envSource{prefix: prefix}
+}
+
+var envReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+func (s *envSource) Lookup(key string) (interface{}, bool) {
+	name := strings.ToUpper(envReplacer.Replace(key))
+	if s.prefix != "" {
+		name = strings.ToUpper(s.prefix) + "_" + name
+	}
+	return os.LookupEnv(name)
+}
+
+type flagSource struct {
+	fs *flag.FlagSet
+}
+
+// NewFlagSource 基于命令行参数的配置源，key 为 db.host 时查找命令行参数 -db.host，只有设置了的参数才存在
+// @param fs *flag.FlagSet "为 nil 时使用 flag.CommandLine"
+func NewFlagSource(fs *flag.FlagSet) ConfigSource {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	return &flagSource{fs: fs}
+}
+
+func (s *flagSource) Lookup(key string) (interface{}, bool) {
+	var value interface{}
+	found := false
+	s.fs.Visit(func(f *flag.Flag) {
+		if f.Name == key {
+			value, found = f.Value.String(), true
+		}
+	})
+	return value, found
+}
@@ -0,0 +1,140 @@
+package ginjects
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+)
+
+type TypeForValueConfig struct {
+	Host    string        `value:"db.host,default=localhost"`
+	Port    int           `value:"db.port"`
+	Timeout time.Duration `value:"db.timeout,default=3s"`
+	Debug   bool          `value:"debug,default=false"`
+	Ratio   float64       `value:"ratio,default=0.5"`
+	Tags    []string      `value:"tags,default=a,b"`
+	Ports   []uint16      `value:"db.ports,default=3306"`
+}
+
+type TypeForValueService struct {
+	Config *TypeForValueConfig `inject:""`
+	Name   string              `value:"service.name"`
+}
+
+func TestValueFromJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	ensure.Nil(t, os.WriteFile(path, []byte(`{"db": {"port": 3307, "timeout": "5s", "ports": [3306, 3307]}, "tags": ["x"], "service.name": "order"}`), 0o644))
+	source, err := NewJSONFileSource(path)
+	ensure.Nil(t, err)
+
+	c := NewContainer()
+	c.AddConfigSource(source)
+	var s TypeForValueService
+	c.ProvideByValue(&s)
+	ensure.Nil(t, c.Init())
+
+	ensure.DeepEqual(t, s.Name, "order")
+	ensure.DeepEqual(t, s.Config.Host, "localhost")
+	ensure.DeepEqual(t, s.Config.Port, 3307)
+	ensure.DeepEqual(t, s.Config.Timeout, 5*time.Second)
+	ensure.False(t, s.Config.Debug)
+	ensure.DeepEqual(t, s.Config.Ratio, 0.5)
+	ensure.DeepEqual(t, s.Config.Tags, []string{"x"})
+	ensure.DeepEqual(t, s.Config.Ports, []uint16{3306, 3307})
+}
+
+func TestValueSourcesOrder(t *testing.T) {
+	t.Setenv("GOBEAN_DB_HOST", "env-host")
+	t.Setenv("GOBEAN_DB_PORT", "0x10")
+	t.Setenv("GOBEAN_DEBUG", "true")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("db.host", "flag-default", "")
+	fs.String("service.name", "", "")
+	ensure.Nil(t, fs.Parse([]string{"-service.name=flag-name"}))
+
+	c := NewContainer()
+	c.AddConfigSource(NewFlagSource(fs), NewEnvSource("gobean"), NewMapSource(map[string]interface{}{
+		"db": map[interface{}]interface{}{"host": "map-host", "timeout": "1ms"},
+	}))
+	var s TypeForValueService
+	c.ProvideByValue(&s)
+	ensure.Nil(t, c.Init())
+
+	ensure.DeepEqual(t, s.Name, "flag-name")
+	ensure.DeepEqual(t, s.Config.Host, "env-host")
+	ensure.DeepEqual(t, s.Config.Port, 16)
+	ensure.DeepEqual(t, s.Config.Timeout, time.Millisecond)
+	ensure.True(t, s.Config.Debug)
+	ensure.DeepEqual(t, s.Config.Tags, []string{"a", "b"})
+}
+
+func TestValueChildAndExisting(t *testing.T) {
+	parent := NewContainer()
+	parent.AddConfigSource(NewMapSource(map[string]interface{}{"db.port": 1, "service.name": "parent"}))
+	child := parent.NewChild()
+	child.AddConfigSource(NewMapSource(map[string]interface{}{"service.name": "child"}))
+	s := &TypeForValueService{Config: &TypeForValueConfig{Port: 2}}
+	child.ProvideByValue(s)
+	child.ProvideByValue(s.Config)
+	ensure.Nil(t, child.Init())
+
+	ensure.DeepEqual(t, s.Name, "child")
+	ensure.DeepEqual(t, s.Config.Port, 2)
+	ensure.DeepEqual(t, s.Config.Host, "localhost")
+}
+
+func TestValueErrors(t *testing.T) {
+	c := NewContainer()
+	c.AddConfigSource(NewMapSource(map[string]interface{}{"db": map[string]interface{}{"port": "abc"}}))
+	c.ProvideByValue(&TypeForValueService{})
+	err := c.Init()
+	ensure.NotNil(t, err)
+	ensure.DeepEqual(t, len(err.(Errors)), 2)
+	ensure.True(t, strings.Contains(err.Error(), "ginjects: did not find config key service.name required by field Name in type *ginjects.TypeForValueService"))
+	ensure.True(t, strings.Contains(err.Error(), "ginjects: can not convert config key db.port to field Port (int) in type *ginjects.TypeForValueConfig"))
+
+	c = NewContainer()
+	c.AddConfigSource(NewMapSource(map[string]interface{}{"db.port": 1.5, "service.name": "s"}))
+	c.ProvideByValue(&TypeForValueService{})
+	ensure.DeepEqual(t, c.Init().Error(), "ginjects: can not convert config key db.port to field Port (int) in type *ginjects.TypeForValueConfig: 1.5 is not an integer")
+}
+
+func TestConvertValue(t *testing.T) {
+	cases := []struct {
+		raw      interface{}
+		expected interface{}
+	}{
+		{"42", int8(42)},
+		{float64(42), uint(42)},
+		{"1.5", float32(1.5)},
+		{json.Number("7"), "7"},
+		{[]interface{}{"1s", "2m"}, []time.Duration{time.Second, 2 * time.Minute}},
+		{"", []int{}},
+	}
+	for _, tc := range cases {
+		v, err := convertValue(tc.raw, reflect.TypeOf(tc.expected))
+		ensure.Nil(t, err)
+		ensure.DeepEqual(t, v.Interface(), tc.expected)
+	}
+
+	// Durations without a unit are rejected.
+	for _, raw := range []interface{}{float64(5), 5, json.Number("5")} {
+		_, err := convertValue(raw, durationType)
+		ensure.NotNil(t, err)
+	}
+
+	_, err := convertValue("300", reflect.TypeOf(int8(0)))
+	ensure.NotNil(t, err)
+	_, err = convertValue(-1, reflect.TypeOf(uint(0)))
+	ensure.NotNil(t, err)
+	_, err = convertValue(map[string]interface{}{}, reflect.TypeOf(""))
+	ensure.NotNil(t, err)
+}
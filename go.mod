@@ -1,6 +1,6 @@
 module github.com/erkesi/gobean
 
-go 1.13
+go 1.18
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -9,4 +9,5 @@ require (
 	github.com/facebookgo/subset v0.0.0-20200203212716-c811ad88dec4 // indirect
 	github.com/golang/mock v1.6.0
 	github.com/maja42/goval v1.2.1
+	github.com/mattn/go-sqlite3 v1.14.16
 )
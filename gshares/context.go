@@ -0,0 +1,25 @@
+package gshares
+
+import (
+	"context"
+	"time"
+)
+
+// WithoutCancel 返回保留 ctx 的值、但永远不会被取消的 Context（同 Go 1.21 的 context.WithoutCancel）
+// @param ctx context.Context "父 Context"
+// @return context.Context "没有 Deadline、Done 永远为 nil 的 Context"
+func WithoutCancel(ctx context.Context) context.Context {
+	return withoutCancelCtx{parent: ctx}
+}
+
+type withoutCancelCtx struct {
+	parent context.Context
+}
+
+func (withoutCancelCtx) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (withoutCancelCtx) Done() <-chan struct{} { return nil }
+
+func (withoutCancelCtx) Err() error { return nil }
+
+func (c withoutCancelCtx) Value(key interface{}) interface{} { return c.parent.Value(key) }
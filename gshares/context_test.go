@@ -0,0 +1,23 @@
+package gshares
+
+import (
+	"context"
+	"testing"
+)
+
+type ctxKey struct{}
+
+func TestWithoutCancel(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+	ctx := WithoutCancel(parent)
+	cancel()
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Fatalf("canceled with the parent")
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatalf("unexpected deadline")
+	}
+	if ctx.Value(ctxKey{}) != "v" {
+		t.Fatalf("lost the value of the parent")
+	}
+}